@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"jordanmurray.xyz/site/internal/cache"
+	"jordanmurray.xyz/site/internal/export"
+	"jordanmurray.xyz/site/internal/routes"
+)
+
+// runBuild exports every route as static files and returns the process exit
+// code.
+func runBuild(args []string) int {
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	out := flags.String("out", "dist", "directory to write the site to")
+	verify := flags.Bool("verify", false, "check the exported files match what the server responds with")
+	_ = flags.Parse(args)
+
+	if _, err := hydrate(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "error hydrating cache: %v\n", err)
+		return 1
+	}
+
+	c, err := cache.Get()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error getting cache: %v\n", err)
+		return 1
+	}
+
+	mux := routes.New("")
+	exported := export.Paths(c)
+
+	if err := export.Site(mux, exported, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "error exporting site: %v\n", err)
+		return 1
+	}
+
+	if *verify {
+		if err := export.Verify(mux, exported, *out); err != nil {
+			fmt.Fprintf(os.Stderr, "exported site does not match server:\n%v\n", err)
+			return 1
+		}
+	}
+
+	fmt.Printf("exported %d routes to %s\n", len(exported), *out)
+	return 0
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"jordanmurray.xyz/site/internal/check"
+)
+
+// runCheck lints the content tree on disk and returns the process exit code.
+func runCheck(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	contentDir := flags.String("content", "content", "directory containing reflections/")
+	staticDir := flags.String("static", "static", "directory served under /static/")
+	_ = flags.Parse(args)
+
+	problems, err := check.Content(os.DirFS(*contentDir), os.DirFS(*staticDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error checking content: %v\n", err)
+		return 1
+	}
+
+	if len(problems) > 0 {
+		fmt.Fprintln(os.Stderr, problems)
+		return 1
+	}
+
+	fmt.Println("content ok")
+	return 0
+}
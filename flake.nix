@@ -24,6 +24,14 @@
 
         version = "latest";
 
+        # lastModifiedDate is YYYYMMDDHHMMSS; reported by /version as RFC 3339
+        buildTime =
+          let
+            date = self.lastModifiedDate or "19700101000000";
+            part = start: len: builtins.substring start len date;
+          in
+          "${part 0 4}-${part 4 2}-${part 6 2}T${part 8 2}:${part 10 2}:${part 12 2}Z";
+
         # Fetch external vendor assets with fixed hashes for reproducibility
         daisyuiCss = pkgs.fetchurl {
           url = "https://cdn.jsdelivr.net/npm/daisyui@4.12.14/dist/full.min.css";
@@ -124,6 +132,10 @@
             cp ${hackFontItalic} static/vendor/fonts/hack-italic.woff2
             cp ${hackFontBoldItalic} static/vendor/fonts/hack-bolditalic.woff2
 
+            # Decompress the fonts used to draw link preview images
+            ${pkgs.woff2}/bin/woff2_decompress static/vendor/fonts/hack-regular.woff2
+            ${pkgs.woff2}/bin/woff2_decompress static/vendor/fonts/hack-bold.woff2
+
             # Copy and patch Hack font CSS to use local paths
             cp ${hackFontCss} static/vendor/css/hack.css
             chmod +w static/vendor/css/hack.css
@@ -135,6 +147,7 @@
 
           ldflags = [
             "-X jordanmurray.xyz/site/version.GitSHA=${self.rev or self.dirtyRev or "unknown"}"
+            "-X jordanmurray.xyz/site/version.BuildTime=${buildTime}"
           ];
 
           postInstall = ''
@@ -13,4 +13,7 @@ require (
 require (
 	github.com/andybalholm/brotli v1.2.0
 	github.com/dlclark/regexp2 v1.7.0 // indirect
+	github.com/klauspost/compress v1.18.0
+	golang.org/x/image v0.18.0
+	golang.org/x/text v0.16.0 // indirect
 )
@@ -0,0 +1,165 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"jordanmurray.xyz/site/internal/utils"
+)
+
+var manifest = &Manifest{}
+
+// Asset is a static file read once at startup, along with precompressed
+// variants when its content type benefits from compression.
+type Asset struct {
+	Name          string
+	Fingerprinted string
+	Compressed    map[utils.Encoding][]byte
+	data          []byte
+	contentType   string
+	etag          string
+}
+
+func (a Asset) Data() []byte {
+	return a.data
+}
+
+func (a Asset) Encoded(enc utils.Encoding) []byte {
+	return a.Compressed[enc]
+}
+
+func (a Asset) ContentType() string {
+	return a.contentType
+}
+
+func (a Asset) ETag() string {
+	return a.etag
+}
+
+func (a Asset) LastModified() time.Time {
+	return time.Time{}
+}
+
+// Manifest indexes assets by both their original and fingerprinted names.
+type Manifest struct {
+	assets        []Asset
+	byName        map[string]int
+	byFingerprint map[string]int
+}
+
+// Load reads every file in fsys, fingerprints it with a hash of its content
+// and precomputes compressed variants. It replaces the current manifest.
+func Load(fsys fs.FS) error {
+	m := &Manifest{
+		byName:        make(map[string]int),
+		byFingerprint: make(map[string]int),
+	}
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("walking error: %w", err)
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		asset, err := loadAsset(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		m.byName[asset.Name] = len(m.assets)
+		m.byFingerprint[asset.Fingerprinted] = len(m.assets)
+		m.assets = append(m.assets, asset)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	manifest = m
+	return nil
+}
+
+func loadAsset(fsys fs.FS, name string) (Asset, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return Asset{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	sum := sha256.Sum256(data)
+	ext := path.Ext(name)
+	contentType := mime.TypeByExtension(ext)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	asset := Asset{
+		Name:          name,
+		Fingerprinted: strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:5]) + ext,
+		data:          data,
+		contentType:   contentType,
+		etag:          fmt.Sprintf(`"%x"`, sum[:16]),
+	}
+
+	if Compressible(contentType) {
+		asset.Compressed, err = utils.CompressAll(data)
+		if err != nil {
+			return Asset{}, fmt.Errorf("failed to compress %s: %w", name, err)
+		}
+	}
+
+	return asset, nil
+}
+
+// Compressible reports whether content of this type is worth compressing;
+// images and fonts other than SVG and icons are already compressed.
+func Compressible(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+xml") || strings.HasSuffix(mediaType, "+json") {
+		return true
+	}
+
+	return slices.Contains([]string{
+		"application/javascript",
+		"application/json",
+		"application/xml",
+		"image/svg+xml",
+		"image/vnd.microsoft.icon",
+		"image/x-icon",
+	}, mediaType)
+}
+
+// Path returns the URL for the static file name, fingerprinted when the
+// file is known so that it can be cached indefinitely.
+func Path(name string) string {
+	if i, ok := manifest.byName[name]; ok {
+		return "/static/" + manifest.assets[i].Fingerprinted
+	}
+	return "/static/" + name
+}
+
+// Lookup finds an asset by its fingerprinted or original name. immutable
+// reports whether it was requested by fingerprint.
+func Lookup(name string) (asset Asset, immutable bool, ok bool) {
+	if i, ok := manifest.byFingerprint[name]; ok {
+		return manifest.assets[i], true, true
+	}
+	if i, ok := manifest.byName[name]; ok {
+		return manifest.assets[i], false, true
+	}
+	return Asset{}, false, false
+}
+
+// All returns every loaded asset.
+func All() []Asset {
+	return manifest.assets
+}
@@ -2,25 +2,118 @@ package cache
 
 import (
 	"context"
-	"embed"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/fs"
-	"path/filepath"
+	"log"
+	"net/url"
 	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"jordanmurray.xyz/site/internal/metrics"
 	"jordanmurray.xyz/site/internal/models"
+	"jordanmurray.xyz/site/internal/ogimage"
 	"jordanmurray.xyz/site/internal/renderer"
+	"jordanmurray.xyz/site/internal/search"
+	"jordanmurray.xyz/site/templates"
 )
 
-var cache = &Cache{}
+var (
+	current atomic.Pointer[Cache]
+	source  hydrator
+)
+
+var (
+	buildDuration = metrics.NewHistogram("site_cache_build_duration_seconds",
+		"Time taken to load and render content into a new snapshot.",
+		metrics.ExponentialBuckets(0.01, 2, 12))
+	hydratedTime = metrics.NewGauge("site_cache_hydrated_timestamp_seconds",
+		"When the current snapshot was built, in seconds since the Unix epoch.")
+	postCount = metrics.NewGauge("site_cache_posts",
+		"Posts visible in the current snapshot.")
+	tagCount = metrics.NewGauge("site_cache_tags",
+		"Tags in the current snapshot.")
+	reloads = metrics.NewCounterVec("site_cache_reloads_total",
+		"Content reloads by result.", "result")
+)
+
+// relatedPosts is the number of related reflections shown under each post.
+const relatedPosts = 3
 
+type Config struct {
+	RSS models.RSSConfig
+	// Preview serves drafts and scheduled posts as though they were published.
+	Preview bool
+	// PageSize is the number of posts per page of the reflections index.
+	PageSize int
+	// Disallow lists the path prefixes robots.txt asks crawlers to skip.
+	Disallow []string
+}
+
+// Cache is an immutable snapshot of everything served to readers. Rebuilds
+// produce a new Cache which is swapped in whole, so a request holding one
+// never observes a partially built state.
 type Cache struct {
+	posts         []models.Post
+	nextPublishAt time.Time
+	baseURL       string
+	hydratedAt    time.Time
+	snapshot      string
+
 	allPosts   []models.Post
 	postBySlug map[string]renderer.RenderedPost
+	ogImages   map[string]renderer.RenderedOGImage
+	previews   map[string]renderer.RenderedOGImage
 	rss        renderer.RenderedRSSFeed
-	once       sync.Once
+	atom       renderer.RenderedAtomFeed
+	jsonFeed   renderer.RenderedJSONFeed
+	tags       []models.Tag
+	tagBySlug  map[string]models.Tag
+	tagRSS     map[string]renderer.RenderedRSSFeed
+	index      *search.Index
+	sitemap    renderer.RenderedSitemap
+	robots     renderer.RenderedRobots
+
+	home            renderer.RenderedPage
+	reflectionPages []renderer.RenderedPage
+	tagIndex        renderer.RenderedPage
+	tagPages        map[string]renderer.RenderedPage
+	archive         []models.ArchiveYear
+	archiveIndex    renderer.RenderedPage
+	yearPages       map[int]renderer.RenderedPage
+	monthPages      map[string]renderer.RenderedPage
+}
+
+// hydrator remembers where content comes from so the cache can be rebuilt
+// at runtime, and serialises rebuilds against each other.
+type hydrator struct {
+	mu     sync.Mutex
+	fsys   fs.FS
+	config Config
+	// publish fires when the earliest scheduled post in the current
+	// snapshot is due.
+	publish *time.Timer
+}
+
+// HydratedAt is when this snapshot was built.
+func (c *Cache) HydratedAt() time.Time {
+	return c.hydratedAt
+}
+
+// Snapshot identifies the content being served: it changes whenever any
+// post or feed does.
+func (c *Cache) Snapshot() string {
+	return c.snapshot
+}
+
+// BaseURL is the absolute URL the site is served from.
+func (c *Cache) BaseURL() string {
+	return c.baseURL
 }
 
 func (c *Cache) AllPosts() []models.Post {
@@ -36,25 +129,171 @@ func (c *Cache) PostBySlug(slug string) (renderer.RenderedPost, error) {
 	return post, nil
 }
 
+func (c *Cache) OGImage(slug string) (renderer.RenderedOGImage, error) {
+	image, ok := c.ogImages[slug]
+	if !ok {
+		return renderer.RenderedOGImage{}, errors.New("could not find preview image")
+	}
+
+	return image, nil
+}
+
 func (c *Cache) RSS() renderer.RenderedRSSFeed {
 	return c.rss
 }
 
-func (c *Cache) storePosts(renderedPosts []renderer.RenderedPost) {
-	posts := make([]models.Post, len(renderedPosts))
-	slugMap := make(map[string]renderer.RenderedPost)
+func (c *Cache) Atom() renderer.RenderedAtomFeed {
+	return c.atom
+}
+
+func (c *Cache) JSONFeed() renderer.RenderedJSONFeed {
+	return c.jsonFeed
+}
+
+func (c *Cache) Tags() []models.Tag {
+	return c.tags
+}
+
+func (c *Cache) TagBySlug(slug string) (models.Tag, error) {
+	tag, ok := c.tagBySlug[slug]
+	if !ok {
+		return models.Tag{}, errors.New("could not find tag")
+	}
+
+	return tag, nil
+}
+
+func (c *Cache) TagRSS(slug string) (renderer.RenderedRSSFeed, error) {
+	feed, ok := c.tagRSS[slug]
+	if !ok {
+		return renderer.RenderedRSSFeed{}, errors.New("could not find tag feed")
+	}
+
+	return feed, nil
+}
+
+// Search returns up to limit posts matching query, best matches first.
+func (c *Cache) Search(query string, limit int) []search.Result {
+	return c.index.Search(query, limit)
+}
+
+func (c *Cache) Sitemap() renderer.RenderedSitemap {
+	return c.sitemap
+}
+
+func (c *Cache) Robots() renderer.RenderedRobots {
+	return c.robots
+}
+
+func (c *Cache) Home() renderer.RenderedPage {
+	return c.home
+}
+
+// ReflectionsPage returns the nth page of the reflections index, counting
+// from 1.
+func (c *Cache) ReflectionsPage(n int) (renderer.RenderedPage, error) {
+	if n < 1 || n > len(c.reflectionPages) {
+		return renderer.RenderedPage{}, errors.New("page out of range")
+	}
+
+	return c.reflectionPages[n-1], nil
+}
+
+func (c *Cache) PageCount() int {
+	return len(c.reflectionPages)
+}
+
+func (c *Cache) TagIndex() renderer.RenderedPage {
+	return c.tagIndex
+}
+
+func (c *Cache) TagPage(slug string) (renderer.RenderedPage, error) {
+	page, ok := c.tagPages[slug]
+	if !ok {
+		return renderer.RenderedPage{}, errors.New("could not find tag page")
+	}
+
+	return page, nil
+}
+
+func (c *Cache) Archive() []models.ArchiveYear {
+	return c.archive
+}
+
+func (c *Cache) ArchiveIndex() renderer.RenderedPage {
+	return c.archiveIndex
+}
+
+func (c *Cache) YearPage(year int) (renderer.RenderedPage, error) {
+	page, ok := c.yearPages[year]
+	if !ok {
+		return renderer.RenderedPage{}, errors.New("could not find year archive")
+	}
+
+	return page, nil
+}
 
-	for i, cp := range renderedPosts {
-		posts[i] = cp.Post
-		slugMap[cp.Slug] = cp
+func (c *Cache) MonthPage(year int, month time.Month) (renderer.RenderedPage, error) {
+	page, ok := c.monthPages[models.MonthURL(year, month)]
+	if !ok {
+		return renderer.RenderedPage{}, errors.New("could not find month archive")
 	}
 
+	return page, nil
+}
+
+func (c *Cache) storePosts(posts []models.Post) {
 	slices.SortFunc(posts, func(a, b models.Post) int {
 		return b.PublishedAt.Compare(a.PublishedAt)
 	})
 
 	c.allPosts = posts
-	c.postBySlug = slugMap
+}
+
+// renderPosts renders every visible post with its chronological neighbours
+// and related posts baked in, along with its link preview image.
+func (c *Cache) renderPosts(ctx context.Context) error {
+	navigation := models.Navigate(c.allPosts, c.index.Related(relatedPosts))
+
+	fonts, err := ogimage.LoadFonts()
+	if err != nil {
+		return fmt.Errorf("error loading preview image fonts: %w", err)
+	}
+	siteName := c.baseURL
+	if u, err := url.Parse(c.baseURL); err == nil && u.Host != "" {
+		siteName = u.Host
+	}
+
+	// Preview images are slow to draw, so those whose content is unchanged
+	// since the previous snapshot are carried over.
+	var previous map[string]renderer.RenderedOGImage
+	if prev := current.Load(); prev != nil {
+		previous = prev.previews
+	}
+
+	c.postBySlug = make(map[string]renderer.RenderedPost, len(c.allPosts))
+	c.ogImages = make(map[string]renderer.RenderedOGImage, len(c.allPosts))
+	c.previews = make(map[string]renderer.RenderedOGImage, len(c.allPosts))
+	for _, post := range c.allPosts {
+		renderedPost, err := renderer.NewRenderedPost(post, navigation[post.Slug], ctx)
+		if err != nil {
+			return fmt.Errorf("error rendering post %s: %w", post.Slug, err)
+		}
+		c.postBySlug[post.Slug] = renderedPost
+
+		key := ogimage.Key(post, siteName)
+		image, ok := previous[key]
+		if !ok {
+			image, err = renderer.NewRenderedOGImage(post, siteName, fonts)
+			if err != nil {
+				return fmt.Errorf("error rendering preview image for %s: %w", post.Slug, err)
+			}
+		}
+		c.ogImages[post.Slug] = image
+		c.previews[key] = image
+	}
+
+	return nil
 }
 
 func (c *Cache) constructRss(rssConfig models.RSSConfig) error {
@@ -73,61 +312,349 @@ func (c *Cache) constructRss(rssConfig models.RSSConfig) error {
 	return nil
 }
 
-func Hydrate(fsys embed.FS, rssConfig models.RSSConfig, ctx context.Context) {
-	cache.once.Do(func() {
-		cachedPosts, err := loadRenderedPosts(fsys, ctx)
-		if err != nil {
-			panic(fmt.Errorf("error loading posts: %w", err))
+func (c *Cache) constructAtom(rssConfig models.RSSConfig) error {
+	atomFeed := models.NewAtomFeed(rssConfig)
+	err := atomFeed.FromPosts(c.allPosts)
+	if err != nil {
+		return fmt.Errorf("failed to generate atom feed: %w", err)
+	}
+
+	renderedAtomFeed, err := renderer.NewRenderedAtomFeed(atomFeed)
+	if err != nil {
+		return fmt.Errorf("failed to compress atom feed: %w", err)
+	}
+	c.atom = renderedAtomFeed
+
+	return nil
+}
+
+func (c *Cache) constructJSONFeed(rssConfig models.RSSConfig) error {
+	jsonFeed := models.NewJSONFeed(rssConfig)
+	err := jsonFeed.FromPosts(c.allPosts)
+	if err != nil {
+		return fmt.Errorf("failed to generate json feed: %w", err)
+	}
+
+	renderedJSONFeed, err := renderer.NewRenderedJSONFeed(jsonFeed)
+	if err != nil {
+		return fmt.Errorf("failed to compress json feed: %w", err)
+	}
+	c.jsonFeed = renderedJSONFeed
+
+	return nil
+}
+
+func (c *Cache) storeTags(rssConfig models.RSSConfig) error {
+	tags := models.GroupByTag(c.allPosts)
+	slices.SortFunc(tags, func(a, b models.Tag) int {
+		return strings.Compare(a.Slug, b.Slug)
+	})
+
+	tagBySlug := make(map[string]models.Tag, len(tags))
+	tagRSS := make(map[string]renderer.RenderedRSSFeed, len(tags))
+
+	for _, tag := range tags {
+		tagConfig := rssConfig
+		tagConfig.Title = fmt.Sprintf("%s // %s", rssConfig.Title, tag.Name)
+
+		rssFeed := models.NewRSSFeed(tagConfig)
+		if err := rssFeed.FromPosts(tag.Posts); err != nil {
+			return fmt.Errorf("failed to generate rss for tag %s: %w", tag.Slug, err)
 		}
 
-		cache.storePosts(cachedPosts)
-		if err := cache.constructRss(rssConfig); err != nil {
-			panic(fmt.Errorf("error caching rss: %w", err))
+		renderedRssFeed, err := renderer.NewRenderedRSSFeed(rssFeed)
+		if err != nil {
+			return fmt.Errorf("failed to compress rss for tag %s: %w", tag.Slug, err)
 		}
-	})
+
+		tagBySlug[tag.Slug] = tag
+		tagRSS[tag.Slug] = renderedRssFeed
+	}
+
+	c.tags = tags
+	c.tagBySlug = tagBySlug
+	c.tagRSS = tagRSS
+
+	return nil
 }
 
-func Get() (*Cache, error) {
-	if cache == nil {
-		return nil, errors.New("cache is nil")
+// constructCrawl builds the sitemap and the robots.txt that points to it.
+// Preview deployments ask crawlers to stay away entirely.
+func (c *Cache) constructCrawl(config Config) error {
+	sitemap := models.NewSitemap(config.RSS)
+	if err := sitemap.FromPosts(c.allPosts, c.tags); err != nil {
+		return fmt.Errorf("failed to generate sitemap: %w", err)
 	}
-	return cache, nil
+
+	renderedSitemap, err := renderer.NewRenderedSitemap(sitemap)
+	if err != nil {
+		return fmt.Errorf("failed to compress sitemap: %w", err)
+	}
+
+	renderedRobots, err := renderer.NewRenderedRobots(models.NewRobots(config.RSS, config.Disallow, config.Preview))
+	if err != nil {
+		return fmt.Errorf("failed to compress robots.txt: %w", err)
+	}
+
+	c.sitemap = renderedSitemap
+	c.robots = renderedRobots
+
+	return nil
 }
 
-func loadRenderedPosts(fsys embed.FS, ctx context.Context) ([]renderer.RenderedPost, error) {
-	var cachedPosts []renderer.RenderedPost
+// renderPages pre-renders every listing page so that, like posts, they are
+// served from memory with compression and validators.
+func (c *Cache) renderPages(pageSize int, ctx context.Context) error {
+	var err error
+
+	if c.home, err = renderer.NewRenderedPage(templates.Home(c.allPosts), ctx); err != nil {
+		return fmt.Errorf("failed to render home: %w", err)
+	}
 
-	err := fs.WalkDir(fsys, "content/reflections", func(path string, d fs.DirEntry, err error) error {
+	pages := models.Paginate(c.allPosts, pageSize)
+	c.reflectionPages = make([]renderer.RenderedPage, len(pages))
+	for i, page := range pages {
+		c.reflectionPages[i], err = renderer.NewRenderedPage(templates.Reflections(page), ctx)
 		if err != nil {
-			return fmt.Errorf("walking error: %w", err)
+			return fmt.Errorf("failed to render reflections page %d: %w", page.Number, err)
 		}
+	}
+
+	if c.tagIndex, err = renderer.NewRenderedPage(templates.Tags(c.tags), ctx); err != nil {
+		return fmt.Errorf("failed to render tag index: %w", err)
+	}
 
-		if d.IsDir() || filepath.Ext(d.Name()) != ".md" {
-			return nil
+	c.tagPages = make(map[string]renderer.RenderedPage, len(c.tags))
+	for _, tag := range c.tags {
+		page, err := renderer.NewRenderedPage(templates.TagReflections(tag), ctx)
+		if err != nil {
+			return fmt.Errorf("failed to render tag %s: %w", tag.Slug, err)
 		}
+		c.tagPages[tag.Slug] = page
+	}
 
-		cachedPost, err := loadAndRenderPost(fsys, path, ctx)
+	return c.renderArchive(ctx)
+}
+
+// renderArchive pre-renders the archive overview along with a page for every
+// year and month that has posts.
+func (c *Cache) renderArchive(ctx context.Context) error {
+	var err error
+
+	c.archive = models.GroupByMonth(c.allPosts)
+	if c.archiveIndex, err = renderer.NewRenderedPage(templates.Archive(c.archive), ctx); err != nil {
+		return fmt.Errorf("failed to render archive: %w", err)
+	}
+
+	c.yearPages = make(map[int]renderer.RenderedPage, len(c.archive))
+	c.monthPages = make(map[string]renderer.RenderedPage)
+	for _, year := range c.archive {
+		page, err := renderer.NewRenderedPage(templates.ArchiveYearPage(year), ctx)
 		if err != nil {
-			return fmt.Errorf("rendering posts: %w", err)
+			return fmt.Errorf("failed to render archive for %d: %w", year.Year, err)
 		}
+		c.yearPages[year.Year] = page
 
-		cachedPosts = append(cachedPosts, cachedPost)
-		return nil
-	})
+		for _, month := range year.Months {
+			page, err := renderer.NewRenderedPage(templates.ArchiveMonthPage(month), ctx)
+			if err != nil {
+				return fmt.Errorf("failed to render archive for %s: %w", month.URL(), err)
+			}
+			c.monthPages[month.URL()] = page
+		}
+	}
 
-	return cachedPosts, err
+	return nil
 }
 
-func loadAndRenderPost(fsys embed.FS, path string, ctx context.Context) (renderer.RenderedPost, error) {
-	post, err := models.LoadPostFromFS(fsys, path)
+// build derives everything served to readers from the posts that are visible
+// at now.
+func build(posts []models.Post, config Config, now time.Time, ctx context.Context) (*Cache, error) {
+	c := &Cache{posts: posts, baseURL: config.RSS.BaseURL}
+	ctx = templates.WithBaseURL(ctx, config.RSS.BaseURL)
+	ctx = templates.WithBuiltAt(ctx, now)
+	var visible []models.Post
+
+	for _, post := range posts {
+		if config.Preview || post.Visible(now) {
+			visible = append(visible, post)
+			continue
+		}
+
+		if !post.Draft && (c.nextPublishAt.IsZero() || post.PublishedAt.Before(c.nextPublishAt)) {
+			c.nextPublishAt = post.PublishedAt
+		}
+	}
+
+	c.storePosts(visible)
+	c.index = search.NewIndex(c.allPosts)
+	if err := c.renderPosts(ctx); err != nil {
+		return nil, fmt.Errorf("error caching posts: %w", err)
+	}
+	if err := c.constructRss(config.RSS); err != nil {
+		return nil, fmt.Errorf("error caching rss: %w", err)
+	}
+	if err := c.constructAtom(config.RSS); err != nil {
+		return nil, fmt.Errorf("error caching atom feed: %w", err)
+	}
+	if err := c.constructJSONFeed(config.RSS); err != nil {
+		return nil, fmt.Errorf("error caching json feed: %w", err)
+	}
+	if err := c.storeTags(config.RSS); err != nil {
+		return nil, fmt.Errorf("error caching tags: %w", err)
+	}
+	if err := c.constructCrawl(config); err != nil {
+		return nil, fmt.Errorf("error caching sitemap: %w", err)
+	}
+	if err := c.renderPages(config.PageSize, ctx); err != nil {
+		return nil, fmt.Errorf("error caching pages: %w", err)
+	}
+
+	c.hydratedAt = now
+	c.snapshot = c.snapshotHash()
+
+	return c, nil
+}
+
+// snapshotHash combines the ETags of every post and feed, in a stable
+// order, into a single identifier for the snapshot.
+func (c *Cache) snapshotHash() string {
+	h := sha256.New()
+	for _, post := range c.allPosts {
+		fmt.Fprintf(h, "%s %s\n", post.Slug, c.postBySlug[post.Slug].ETag())
+	}
+	for _, feed := range []renderer.Renderer{c.rss, c.atom, c.jsonFeed, c.sitemap} {
+		fmt.Fprintf(h, "%s\n", feed.ETag())
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func (c *Cache) publishDue(now time.Time) bool {
+	return !c.nextPublishAt.IsZero() && !now.Before(c.nextPublishAt)
+}
+
+// publishScheduled rebuilds the current snapshot once the earliest scheduled
+// post has reached its published_at time, so it goes live without a redeploy.
+// It runs from the publish timer; readers keep being served the previous
+// snapshot until the new one is swapped in.
+func publishScheduled() {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	now := time.Now()
+	c := current.Load()
+	if !c.publishDue(now) {
+		// The timer runs on the monotonic clock, so a wall clock stepped
+		// backwards can fire it early; wait for the post's time again.
+		if source.publish != nil {
+			source.publish.Stop()
+		}
+		if !c.nextPublishAt.IsZero() {
+			source.publish = time.AfterFunc(time.Until(c.nextPublishAt), publishScheduled)
+		}
+		return
+	}
+
+	next, err := build(c.posts, source.config, now, context.Background())
 	if err != nil {
-		return renderer.RenderedPost{}, fmt.Errorf("error loading post from %s: %w", path, err)
+		log.Printf("error publishing scheduled posts: %v", err)
+		return
+	}
+
+	store(next, time.Since(now))
+}
+
+// store makes c the current snapshot, schedules its next publish and
+// updates the cache metrics. source.mu must be held.
+func store(c *Cache, took time.Duration) {
+	current.Store(c)
+
+	if source.publish != nil {
+		source.publish.Stop()
+		source.publish = nil
+	}
+	if !c.nextPublishAt.IsZero() {
+		source.publish = time.AfterFunc(time.Until(c.nextPublishAt), publishScheduled)
 	}
 
-	cachedPost, err := renderer.NewRenderedPost(post, ctx)
+	buildDuration.Observe(took.Seconds())
+	hydratedTime.Set(float64(c.hydratedAt.UnixNano()) / 1e9)
+	postCount.Set(float64(len(c.allPosts)))
+	tagCount.Set(float64(len(c.tags)))
+}
+
+// Hydrate loads and renders all content from fsys and makes it the current
+// snapshot. fsys is retained so that Reload can rebuild from it later. If any
+// post is invalid the returned error wraps models.Problems describing all of
+// them.
+func Hydrate(fsys fs.FS, config Config, ctx context.Context) error {
+	source.mu.Lock()
+	source.fsys = fsys
+	source.config = config
+	source.mu.Unlock()
+
+	return reload(ctx)
+}
+
+// Reload re-reads and re-renders every post from the hydrated content source
+// and swaps in the new snapshot. On error the previous snapshot keeps serving.
+func Reload(ctx context.Context) error {
+	if err := reload(ctx); err != nil {
+		reloads.Inc("failure")
+		return err
+	}
+
+	reloads.Inc("success")
+	return nil
+}
+
+func reload(ctx context.Context) error {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	if source.fsys == nil {
+		return errors.New("cache has not been hydrated")
+	}
+
+	start := time.Now()
+	posts, err := loadPosts(source.fsys)
 	if err != nil {
-		return renderer.RenderedPost{}, fmt.Errorf("error rendering post %s: %w", post.Slug, err)
+		return fmt.Errorf("error loading posts: %w", err)
 	}
 
-	return cachedPost, nil
+	next, err := build(posts, source.config, time.Now(), ctx)
+	if err != nil {
+		return err
+	}
+
+	store(next, time.Since(start))
+	return nil
+}
+
+func Get() (*Cache, error) {
+	c := current.Load()
+	if c == nil {
+		return nil, errors.New("cache is nil")
+	}
+
+	return c, nil
+}
+
+// loadPosts loads every post under reflections/. Problems with individual
+// posts are returned together as models.Problems.
+func loadPosts(fsys fs.FS) ([]models.Post, error) {
+	sources, problems, err := models.LoadPosts(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	posts := make([]models.Post, len(sources))
+	for i, src := range sources {
+		posts[i] = src.Post
+	}
+	return posts, nil
 }
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+)
+
+// Watch polls dir every interval and reloads the cache whenever a file
+// beneath it is added, removed or modified. It returns once ctx is done.
+func Watch(ctx context.Context, dir string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last, err := fingerprint(dir)
+	if err != nil {
+		log.Printf("error fingerprinting %s: %v", dir, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		fp, err := fingerprint(dir)
+		if err != nil {
+			log.Printf("error fingerprinting %s: %v", dir, err)
+			continue
+		}
+		if fp == last {
+			continue
+		}
+		last = fp
+
+		if err := Reload(ctx); err != nil {
+			log.Printf("error reloading content: %v", err)
+			continue
+		}
+		log.Printf("reloaded content from %s", dir)
+	}
+}
+
+func fingerprint(dir string) (uint64, error) {
+	h := fnv.New64a()
+
+	err := fs.WalkDir(os.DirFS(dir), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(h, "%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+
+	return h.Sum64(), err
+}
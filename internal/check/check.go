@@ -0,0 +1,243 @@
+package check
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+
+	"jordanmurray.xyz/site/internal/models"
+)
+
+// Content lints every post under reflections/ in contentFS, resolving
+// /static/ references against staticFS. It returns the problems found; the
+// error is reserved for failures reading the content tree itself.
+func Content(contentFS, staticFS fs.FS) (models.Problems, error) {
+	sources, problems, err := models.LoadPosts(contentFS)
+	if err != nil {
+		return nil, err
+	}
+
+	pathBySlug := make(map[string]string, len(sources))
+	posts := make([]models.Post, len(sources))
+	for i, src := range sources {
+		pathBySlug[src.Slug] = src.Path
+		posts[i] = src.Post
+	}
+
+	tagSlugs := make(map[string]bool)
+	for _, tag := range models.GroupByTag(posts) {
+		tagSlugs[tag.Slug] = true
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	for _, src := range sources {
+		doc := md.Parser().Parse(text.NewReader(src.Markdown))
+		l := linter{
+			Source:   src,
+			slugs:    pathBySlug,
+			tagSlugs: tagSlugs,
+			staticFS: staticFS,
+		}
+		problems = append(problems, l.lint(doc)...)
+	}
+
+	return problems, nil
+}
+
+type linter struct {
+	models.Source
+	slugs    map[string]string
+	tagSlugs map[string]bool
+	staticFS fs.FS
+	problems models.Problems
+}
+
+func (l *linter) lint(doc ast.Node) models.Problems {
+	// The post title is rendered as the page's h1.
+	previousLevel := 1
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Heading:
+			if node.Level > previousLevel+1 {
+				l.report(n, fmt.Sprintf("heading h%d skips a level after h%d", node.Level, previousLevel))
+			}
+			previousLevel = node.Level
+		case *ast.Link:
+			l.checkReference(n, string(node.Destination))
+		case *ast.AutoLink:
+			l.checkReference(n, string(node.URL(l.Markdown)))
+		case *ast.Image:
+			if strings.TrimSpace(l.altText(node)) == "" {
+				l.report(n, fmt.Sprintf("image %s has no alt text", node.Destination))
+			}
+			l.checkReference(n, string(node.Destination))
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return l.problems
+}
+
+// checkReference reports links to reflections that do not exist and
+// references to /static/ files that are missing.
+func (l *linter) checkReference(n ast.Node, destination string) {
+	ref, err := url.Parse(destination)
+	if err != nil {
+		l.report(n, fmt.Sprintf("malformed link %q", destination))
+		return
+	}
+
+	if ref.IsAbs() || ref.Host != "" || ref.Path == "" {
+		return
+	}
+
+	base := &url.URL{Path: "/reflections/" + l.Slug}
+	target := path.Clean(base.ResolveReference(ref).Path)
+
+	switch {
+	case strings.HasPrefix(target, "/static/"):
+		if _, err := fs.Stat(l.staticFS, strings.TrimPrefix(target, "/static/")); err != nil {
+			l.report(n, fmt.Sprintf("missing static asset %s", target))
+		}
+	case strings.HasPrefix(target, "/reflections/tags/"):
+		tag, _, _ := strings.Cut(strings.TrimPrefix(target, "/reflections/tags/"), "/")
+		if !l.tagSlugs[tag] {
+			l.report(n, fmt.Sprintf("link to unknown tag %s", target))
+		}
+	case strings.HasPrefix(target, "/reflections/"):
+		slug := strings.TrimPrefix(target, "/reflections/")
+		// Nested paths are pagination and month archives, and numeric ones
+		// are year archives; only single-segment slugs name a post.
+		if strings.Contains(slug, "/") || models.ReservedSlug(slug) {
+			return
+		}
+		if _, ok := l.slugs[slug]; !ok {
+			l.report(n, fmt.Sprintf("broken link to reflection %s", target))
+		}
+	}
+}
+
+func (l *linter) altText(img *ast.Image) string {
+	var b strings.Builder
+	_ = ast.Walk(img, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := n.(*ast.Text); ok && entering {
+			b.Write(t.Value(l.Markdown))
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+func (l *linter) report(n ast.Node, message string) {
+	l.problems = append(l.problems, models.Problem{
+		Path:    l.Path,
+		Line:    l.line(n),
+		Message: message,
+	})
+}
+
+// line finds the source line of n. Inline nodes do not record their
+// position, so it is worked out from their text or destination, falling back
+// to the first line of the nearest block ancestor.
+func (l *linter) line(n ast.Node) int {
+	if n.Type() == ast.TypeInline {
+		if offset, ok := l.inlineOffset(n); ok {
+			return l.LineOffset + bytes.Count(l.Markdown[:offset], []byte("\n")) + 1
+		}
+	}
+
+	for ; n != nil; n = n.Parent() {
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			offset := n.Lines().At(0).Start
+			return l.LineOffset + bytes.Count(l.Markdown[:offset], []byte("\n")) + 1
+		}
+	}
+	return 0
+}
+
+// inlineOffset locates an inline node by the first text inside it, such as a
+// link's label. Nodes without text, like images without alt text and
+// autolinks, are found by searching for their destination after whatever
+// precedes them in the paragraph.
+func (l *linter) inlineOffset(n ast.Node) (int, bool) {
+	if segment, ok := firstText(n); ok {
+		return segment.Start, true
+	}
+
+	var destination []byte
+	switch node := n.(type) {
+	case *ast.Link:
+		destination = node.Destination
+	case *ast.Image:
+		destination = node.Destination
+	case *ast.AutoLink:
+		destination = node.URL(l.Markdown)
+	}
+	if len(destination) == 0 {
+		return 0, false
+	}
+
+	from := -1
+	for prev := n.PreviousSibling(); prev != nil && from < 0; prev = prev.PreviousSibling() {
+		if segment, ok := lastText(prev); ok {
+			from = segment.Stop
+		}
+	}
+	if from < 0 {
+		for block := n.Parent(); block != nil; block = block.Parent() {
+			if block.Type() == ast.TypeBlock && block.Lines().Len() > 0 {
+				from = block.Lines().At(0).Start
+				break
+			}
+		}
+	}
+	if from < 0 {
+		return 0, false
+	}
+
+	i := bytes.Index(l.Markdown[from:], destination)
+	if i < 0 {
+		return 0, false
+	}
+	return from + i, true
+}
+
+// firstText returns the source segment of the first text node within n.
+func firstText(n ast.Node) (text.Segment, bool) {
+	if t, ok := n.(*ast.Text); ok {
+		return t.Segment, true
+	}
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if segment, ok := firstText(child); ok {
+			return segment, true
+		}
+	}
+	return text.Segment{}, false
+}
+
+// lastText returns the source segment of the last text node within n.
+func lastText(n ast.Node) (text.Segment, bool) {
+	if t, ok := n.(*ast.Text); ok {
+		return t.Segment, true
+	}
+	for child := n.LastChild(); child != nil; child = child.PreviousSibling() {
+		if segment, ok := lastText(child); ok {
+			return segment, true
+		}
+	}
+	return text.Segment{}, false
+}
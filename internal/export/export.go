@@ -0,0 +1,248 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/andybalholm/brotli"
+
+	"jordanmurray.xyz/site/internal/assets"
+	"jordanmurray.xyz/site/internal/cache"
+	"jordanmurray.xyz/site/internal/models"
+	"jordanmurray.xyz/site/internal/utils"
+)
+
+// Route is a path to export. Pages are written as directory indexes so
+// that /reflections/foo resolves on a file host; everything else, such as
+// feeds, images and static assets, is written under its own name.
+type Route struct {
+	Path string
+	Page bool
+}
+
+func page(p string) Route {
+	return Route{Path: p, Page: true}
+}
+
+func file(p string) Route {
+	return Route{Path: p}
+}
+
+// Paths lists every GET route served for the snapshot c, including each
+// static asset under both its original and fingerprinted names.
+func Paths(c *cache.Cache) []Route {
+	routes := []Route{
+		page("/"),
+		page("/reflections"),
+		page("/reflections/tags"),
+		page("/reflections/archive"),
+		file("/reflections/feed.rss"),
+		file("/reflections/feed.atom"),
+		file("/reflections/feed.json"),
+		file("/sitemap.xml"),
+		file("/robots.txt"),
+	}
+
+	for n := 2; n <= c.PageCount(); n++ {
+		routes = append(routes, page(models.PageURL(n)))
+	}
+
+	for _, post := range c.AllPosts() {
+		routes = append(routes,
+			page("/reflections/"+post.Slug),
+			file("/reflections/"+post.Slug+"/og.png"),
+		)
+	}
+
+	for _, year := range c.Archive() {
+		routes = append(routes, page(year.URL()))
+		for _, month := range year.Months {
+			routes = append(routes, page(month.URL()))
+		}
+	}
+
+	for _, tag := range c.Tags() {
+		routes = append(routes,
+			page("/reflections/tags/"+tag.Slug),
+			file("/reflections/tags/"+tag.Slug+"/feed.rss"),
+		)
+	}
+
+	for _, asset := range assets.All() {
+		routes = append(routes,
+			file("/static/"+asset.Name),
+			file("/static/"+asset.Fingerprinted),
+		)
+	}
+
+	return routes
+}
+
+// siblings are the precompressed variants written next to each compressible
+// file, for servers that negotiate encodings from precompressed files.
+var siblings = []struct {
+	suffix   string
+	encoding utils.Encoding
+	compress func([]byte) ([]byte, error)
+	decode   func(io.Reader) (io.Reader, error)
+}{
+	{
+		suffix:   ".br",
+		encoding: utils.Brotli,
+		compress: func(data []byte) ([]byte, error) { return utils.Compress(data, utils.BestCompression) },
+		decode:   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+	},
+	{
+		suffix:   ".gz",
+		encoding: utils.Gzip,
+		compress: utils.CompressGzip,
+		decode:   func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	},
+}
+
+// Site requests each path from handler and writes the response under dir,
+// alongside .br and .gz siblings when its type is worth compressing. The
+// siblings hold the bytes the server sends for those encodings, so the
+// precompressed variants are reused rather than compressed again.
+func Site(handler http.Handler, routes []Route, dir string) error {
+	for _, route := range routes {
+		p := route.Path
+		resp, err := fetch(handler, p, "identity")
+		if err != nil {
+			return err
+		}
+
+		files := map[string][]byte{}
+		name := filepath.Join(dir, filePath(route))
+		files[name] = resp.Body.Bytes()
+
+		if assets.Compressible(resp.Header().Get("Content-Type")) {
+			for _, sibling := range siblings {
+				data, err := encoded(handler, p, sibling.encoding, sibling.compress)
+				if err != nil {
+					return err
+				}
+				files[name+sibling.suffix] = data
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", p, err)
+		}
+
+		for file, data := range files {
+			if err := os.WriteFile(file, data, 0o644); err != nil {
+				return fmt.Errorf("writing %s: %w", file, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// encoded returns p as served to a client accepting only enc, compressing
+// the identity response with compress if the server sends it unencoded.
+func encoded(handler http.Handler, p string, enc utils.Encoding, compress func([]byte) ([]byte, error)) ([]byte, error) {
+	resp, err := fetch(handler, p, string(enc))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Header().Get("Content-Encoding") == string(enc) {
+		return resp.Body.Bytes(), nil
+	}
+
+	data, err := compress(resp.Body.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("compressing %s: %w", p, err)
+	}
+	return data, nil
+}
+
+// Verify re-requests each path from handler and checks that the exported
+// file and any compressed siblings decode to exactly the bytes served.
+func Verify(handler http.Handler, routes []Route, dir string) error {
+	var errs []error
+
+	for _, route := range routes {
+		p := route.Path
+		resp, err := fetch(handler, p, "identity")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		want := resp.Body.Bytes()
+
+		name := filepath.Join(dir, filePath(route))
+		identity := func(r io.Reader) (io.Reader, error) { return r, nil }
+		if err := compareFile(name, identity, want); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p, err))
+		}
+
+		if !assets.Compressible(resp.Header().Get("Content-Type")) {
+			continue
+		}
+		for _, sibling := range siblings {
+			if err := compareFile(name+sibling.suffix, sibling.decode, want); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// fetch requests p from handler as a client accepting acceptEncoding.
+func fetch(handler http.Handler, p, acceptEncoding string) (*httptest.ResponseRecorder, error) {
+	req := httptest.NewRequest(http.MethodGet, p, nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		return nil, fmt.Errorf("requesting %s: status %d", p, rec.Code)
+	}
+
+	return rec, nil
+}
+
+func compareFile(file string, decode func(io.Reader) (io.Reader, error), want []byte) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := decode(f)
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", file, err)
+	}
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", file, err)
+	}
+
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("%s does not match the served response", file)
+	}
+
+	return nil
+}
+
+// filePath maps a route onto the file, relative to the export directory,
+// that serves it.
+func filePath(route Route) string {
+	if route.Page {
+		return filepath.FromSlash(path.Join(route.Path, "index.html"))
+	}
+	return filepath.FromSlash(route.Path)
+}
@@ -0,0 +1,181 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andybalholm/brotli"
+
+	"jordanmurray.xyz/site/internal/assets"
+	"jordanmurray.xyz/site/internal/cache"
+	"jordanmurray.xyz/site/internal/models"
+	"jordanmurray.xyz/site/internal/routes"
+)
+
+var content = fstest.MapFS{
+	"reflections/first-post.md": {Data: []byte(`---
+title: "First post"
+author: "Jordan Murray"
+published_at: 2024-03-01T00:00:00Z
+excerpt: "The first one."
+tags:
+  - go
+  - notes
+---
+
+Hello, world.
+
+## A heading
+
+Some more text.
+`)},
+	"reflections/go-1.22-notes.md": {Data: []byte(`---
+title: "Go 1.22 notes"
+author: "Jordan Murray"
+published_at: 2024-02-10T00:00:00Z
+excerpt: "Range over integers."
+tags:
+  - go
+---
+
+` + "```go\nfor i := range 10 {\n\tfmt.Println(i)\n}\n```\n")},
+}
+
+var static = fstest.MapFS{
+	"css/custom.css": {Data: []byte("body { color: black; }\n")},
+	"img/logo.svg":   {Data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`)},
+}
+
+// TestSiteMatchesHandlers exports a fixture site and checks that every file
+// and its precompressed siblings decode to what the server sends a client
+// asking for the same encoding, and that already compressed types such as
+// preview images get no siblings.
+func TestSiteMatchesHandlers(t *testing.T) {
+	if err := assets.Load(static); err != nil {
+		t.Fatalf("loading assets: %v", err)
+	}
+
+	config := cache.Config{
+		RSS: models.RSSConfig{
+			BaseURL:     "https://example.com",
+			Title:       "example",
+			Description: "an example",
+			Author:      "Jordan Murray",
+		},
+		PageSize: models.DefaultPageSize,
+	}
+	if err := cache.Hydrate(content, config, context.Background()); err != nil {
+		t.Fatalf("hydrating cache: %v", err)
+	}
+
+	c, err := cache.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := routes.New("")
+	dir := t.TempDir()
+	exported := Paths(c)
+	if err := Site(mux, exported, dir); err != nil {
+		t.Fatalf("exporting site: %v", err)
+	}
+
+	variants := []struct {
+		suffix         string
+		acceptEncoding string
+		decode         func(io.Reader) (io.Reader, error)
+	}{
+		{"", "identity", identity},
+		{".br", "br", decodeBrotli},
+		{".gz", "gzip", decodeGzip},
+	}
+
+	for _, route := range exported {
+		for _, v := range variants {
+			name := filepath.Join(dir, filePath(route)) + v.suffix
+			t.Run(route.Path+v.suffix, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, route.Path, nil)
+				req.Header.Set("Accept-Encoding", v.acceptEncoding)
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+
+				if rec.Code != http.StatusOK {
+					t.Fatalf("status %d", rec.Code)
+				}
+
+				if v.suffix != "" && !assets.Compressible(rec.Header().Get("Content-Type")) {
+					if _, err := os.Stat(name); !errors.Is(err, fs.ErrNotExist) {
+						t.Errorf("wrote a compressed sibling for %s: %v", rec.Header().Get("Content-Type"), err)
+					}
+					return
+				}
+
+				raw, err := os.ReadFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if rec.Header().Get("Content-Encoding") == v.acceptEncoding && !bytes.Equal(raw, rec.Body.Bytes()) {
+					t.Errorf("%s was compressed again rather than reusing the served %s body", name, v.acceptEncoding)
+				}
+
+				got := readDecoded(t, bytes.NewReader(raw), v.decode)
+
+				decode := identity
+				switch enc := rec.Header().Get("Content-Encoding"); enc {
+				case "":
+				case "br":
+					decode = decodeBrotli
+				case "gzip":
+					decode = decodeGzip
+				default:
+					t.Fatalf("served unexpected Content-Encoding %q for Accept-Encoding %q", enc, v.acceptEncoding)
+				}
+				want := readDecoded(t, rec.Body, decode)
+
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s differs from the served response: got %d bytes, want %d", name, len(got), len(want))
+				}
+			})
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "reflections", "go-1.22-notes", "index.html")); err != nil {
+		t.Errorf("dotted slug was not exported as a directory index: %v", err)
+	}
+}
+
+func identity(r io.Reader) (io.Reader, error) {
+	return r, nil
+}
+
+func decodeBrotli(r io.Reader) (io.Reader, error) {
+	return brotli.NewReader(r), nil
+}
+
+func decodeGzip(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+func readDecoded(t *testing.T, r io.Reader, decode func(io.Reader) (io.Reader, error)) []byte {
+	t.Helper()
+
+	decoded, err := decode(r)
+	if err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	data, err := io.ReadAll(decoded)
+	if err != nil {
+		t.Fatalf("reading: %v", err)
+	}
+	return data
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+
+	"jordanmurray.xyz/site/internal/cache"
+)
+
+// HandleReload rebuilds the content cache. Requests must carry
+// "Authorization: Bearer <token>".
+func HandleReload(token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		supplied, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(supplied), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if err := cache.Reload(r.Context()); err != nil {
+			log.Printf("Error reloading content: %v", err)
+			http.Error(w, "reload failed", http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write([]byte("reloaded"))
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"jordanmurray.xyz/site/internal/cache"
+	"jordanmurray.xyz/site/version"
+)
+
+var draining atomic.Bool
+
+// Drain marks the server as shutting down so that /readyz reports it is no
+// longer ready and orchestrators stop routing new requests to it.
+func Drain() {
+	draining.Store(true)
+}
+
+// HandleHealthz reports that the process is alive and able to serve requests.
+func HandleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write([]byte("ok"))
+}
+
+// HandleReadyz reports whether the server should receive traffic: it is not
+// shutting down and holds a hydrated snapshot with posts and a feed.
+func HandleReadyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if draining.Load() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	c, err := cache.Get()
+	if err != nil {
+		http.Error(w, "cache not hydrated", http.StatusServiceUnavailable)
+		return
+	}
+	if len(c.AllPosts()) == 0 {
+		http.Error(w, "no posts", http.StatusServiceUnavailable)
+		return
+	}
+	if c.RSS().Empty() {
+		http.Error(w, "feed empty", http.StatusServiceUnavailable)
+		return
+	}
+
+	_, _ = w.Write([]byte("ok"))
+}
+
+type versionResponse struct {
+	version.Info
+	Snapshot   string     `json:"snapshot,omitempty"`
+	HydratedAt *time.Time `json:"hydrated_at,omitempty"`
+}
+
+// HandleVersion describes the running build and the content snapshot it is
+// serving.
+func HandleVersion(w http.ResponseWriter, r *http.Request) {
+	response := versionResponse{Info: version.Get()}
+	if c, err := cache.Get(); err == nil {
+		hydratedAt := c.HydratedAt()
+		response.Snapshot = c.Snapshot()
+		response.HydratedAt = &hydratedAt
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(response); err != nil {
+		log.Printf("error encoding version: %v", err)
+	}
+}
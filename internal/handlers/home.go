@@ -1,19 +1,14 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
 
 	"jordanmurray.xyz/site/internal/cache"
-	"jordanmurray.xyz/site/templates"
+	"jordanmurray.xyz/site/internal/renderer"
 )
 
 func HandleHome(w http.ResponseWriter, r *http.Request) {
 	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
-		component := templates.Home(c.AllPosts())
-		if err := component.Render(r.Context(), w); err != nil {
-			log.Printf("Error rendering home: %v", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		renderer.Write(w, r, c.Home())
 	})(w, r)
 }
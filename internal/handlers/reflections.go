@@ -1,21 +1,52 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"jordanmurray.xyz/site/internal/cache"
+	"jordanmurray.xyz/site/internal/models"
 	"jordanmurray.xyz/site/internal/renderer"
-	"jordanmurray.xyz/site/templates"
 )
 
 func HandleReflections(w http.ResponseWriter, r *http.Request) {
 	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
-		component := templates.Reflections(c.AllPosts())
-		if err := component.Render(r.Context(), w); err != nil {
-			log.Printf("Error rendering reflections list: %v", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		page, err := c.ReflectionsPage(1)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		renderer.Write(w, r, page)
+	})(w, r)
+}
+
+func HandleReflectionsPage(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		n, err := strconv.Atoi(r.PathValue("n"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		if n == 1 {
+			http.Redirect(w, r, models.PageURL(1), http.StatusMovedPermanently)
+			return
+		}
+
+		page, err := c.ReflectionsPage(n)
+		if err != nil {
+			http.NotFound(w, r)
+			return
 		}
+
+		if canonical := models.PageURL(n); r.URL.Path != canonical {
+			http.Redirect(w, r, canonical, http.StatusMovedPermanently)
+			return
+		}
+
+		renderer.Write(w, r, page)
 	})(w, r)
 }
 
@@ -24,12 +55,24 @@ func HandleReflection(w http.ResponseWriter, r *http.Request) {
 		slug := r.PathValue("slug")
 
 		cachedPost, err := c.PostBySlug(slug)
-		if err != nil {
-			http.NotFound(w, r)
+		if err == nil {
+			renderer.Write(w, r, cachedPost)
 			return
 		}
 
-		renderer.Write(w, r, cachedPost)
+		// Year archives share the /reflections/{slug} pattern with posts.
+		if year, err := strconv.Atoi(slug); err == nil {
+			if page, err := c.YearPage(year); err == nil {
+				if canonical := models.YearURL(year); r.URL.Path != canonical {
+					http.Redirect(w, r, canonical, http.StatusMovedPermanently)
+					return
+				}
+				renderer.Write(w, r, page)
+				return
+			}
+		}
+
+		http.NotFound(w, r)
 	})(w, r)
 }
 
@@ -43,3 +86,88 @@ func HandleRSS(w http.ResponseWriter, r *http.Request) {
 		renderer.Write(w, r, c.RSS())
 	})(w, r)
 }
+
+func HandleAtom(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		if c.Atom().Empty() {
+			http.Error(w, "Atom feed not available", http.StatusInternalServerError)
+			return
+		}
+
+		renderer.Write(w, r, c.Atom())
+	})(w, r)
+}
+
+func HandleJSONFeed(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		if c.JSONFeed().Empty() {
+			http.Error(w, "JSON feed not available", http.StatusInternalServerError)
+			return
+		}
+
+		renderer.Write(w, r, c.JSONFeed())
+	})(w, r)
+}
+
+func HandleArchive(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		renderer.Write(w, r, c.ArchiveIndex())
+	})(w, r)
+}
+
+// HandleReflectionSubpath serves /reflections/{parent}/{child}, which is
+// either a month archive or a post's preview image. The image can't have its
+// own /reflections/{slug}/og.png pattern: ServeMux panics on registration
+// because it and /reflections/tags/{tag} both match /reflections/tags/og.png
+// and neither is more specific, so both kinds of page share this pattern.
+func HandleReflectionSubpath(w http.ResponseWriter, r *http.Request) {
+	parent, child := r.PathValue("parent"), r.PathValue("child")
+
+	if child == "og.png" {
+		r.SetPathValue("slug", parent)
+		HandleOGImage(w, r)
+		return
+	}
+
+	r.SetPathValue("year", parent)
+	r.SetPathValue("month", child)
+	HandleMonthArchive(w, r)
+}
+
+// HandleOGImage serves the link preview image of the post {slug}.
+func HandleOGImage(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		image, err := c.OGImage(r.PathValue("slug"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		renderer.Write(w, r, image)
+	})(w, r)
+}
+
+// HandleMonthArchive serves the archive page for {year}/{month}.
+func HandleMonthArchive(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		year, yearErr := strconv.Atoi(r.PathValue("year"))
+		month, monthErr := strconv.Atoi(r.PathValue("month"))
+		if yearErr != nil || monthErr != nil || month < 1 || month > 12 {
+			http.NotFound(w, r)
+			return
+		}
+
+		page, err := c.MonthPage(year, time.Month(month))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		if canonical := models.MonthURL(year, time.Month(month)); r.URL.Path != canonical {
+			http.Redirect(w, r, canonical, http.StatusMovedPermanently)
+			return
+		}
+
+		renderer.Write(w, r, page)
+	})(w, r)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"jordanmurray.xyz/site/internal/cache"
+	"jordanmurray.xyz/site/templates"
+)
+
+// searchLimit caps the number of results shown for a query.
+const searchLimit = 20
+
+func HandleSearch(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		query := searchQuery(r)
+		ctx := templates.WithBaseURL(r.Context(), c.BaseURL())
+		ctx = templates.WithBuiltAt(ctx, c.HydratedAt())
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := templates.Search(query, c.Search(query, searchLimit)).Render(ctx, w); err != nil {
+			log.Printf("error rendering search: %v", err)
+		}
+	})(w, r)
+}
+
+// HandleSearchResults renders just the results list for as-you-type search.
+// Datastar asks for a server-sent event stream and merges the fragment by
+// id; other clients get the fragment as plain HTML.
+func HandleSearchResults(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		query := searchQuery(r)
+
+		var buf bytes.Buffer
+		if err := templates.SearchResults(query, c.Search(query, searchLimit)).Render(r.Context(), &buf); err != nil {
+			log.Printf("error rendering search results: %v", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Vary", "Accept")
+		w.Header().Set("Cache-Control", "no-store")
+
+		if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write(buf.Bytes())
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		var event bytes.Buffer
+		event.WriteString("event: datastar-fragment\n")
+		scanner := bufio.NewScanner(&buf)
+		scanner.Buffer(nil, buf.Len()+1)
+		for scanner.Scan() {
+			fmt.Fprintf(&event, "data: fragment %s\n", scanner.Text())
+		}
+		event.WriteString("\n")
+		_, _ = w.Write(event.Bytes())
+	})(w, r)
+}
+
+// searchQuery reads the query from the q parameter, or from the store
+// Datastar sends along with its requests.
+func searchQuery(r *http.Request) string {
+	if store := r.URL.Query().Get("datastar"); store != "" {
+		var signals struct {
+			Q string `json:"q"`
+		}
+		if err := json.Unmarshal([]byte(store), &signals); err == nil {
+			return strings.TrimSpace(signals.Q)
+		}
+	}
+
+	return strings.TrimSpace(r.URL.Query().Get("q"))
+}
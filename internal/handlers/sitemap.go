@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"jordanmurray.xyz/site/internal/cache"
+	"jordanmurray.xyz/site/internal/renderer"
+)
+
+func HandleSitemap(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		renderer.Write(w, r, c.Sitemap())
+	})(w, r)
+}
+
+func HandleRobots(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		renderer.Write(w, r, c.Robots())
+	})(w, r)
+}
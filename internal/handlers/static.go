@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"jordanmurray.xyz/site/internal/assets"
+	"jordanmurray.xyz/site/internal/renderer"
+)
+
+func HandleStatic(w http.ResponseWriter, r *http.Request) {
+	asset, immutable, ok := assets.Lookup(r.PathValue("path"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if immutable {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	}
+
+	renderer.Write(w, r, asset)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"jordanmurray.xyz/site/internal/cache"
+	"jordanmurray.xyz/site/internal/renderer"
+)
+
+func HandleTags(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		renderer.Write(w, r, c.TagIndex())
+	})(w, r)
+}
+
+func HandleTag(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		page, err := c.TagPage(r.PathValue("tag"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		renderer.Write(w, r, page)
+	})(w, r)
+}
+
+func HandleTagRSS(w http.ResponseWriter, r *http.Request) {
+	withCache(func(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+		feed, err := c.TagRSS(r.PathValue("tag"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		renderer.Write(w, r, feed)
+	})(w, r)
+}
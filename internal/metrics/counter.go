@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"io"
+	"slices"
+	"sync"
+)
+
+// CounterVec is a family of counters partitioned by label values.
+type CounterVec struct {
+	metric string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	values []string
+	count  float64
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{metric: name, help: help, labels: labels, series: make(map[string]*counterSeries)}
+	register(c)
+	return c
+}
+
+// Inc adds one to the counter identified by values.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta, which must not be negative, to the counter identified by
+// values.
+func (c *CounterVec) Add(delta float64, values ...string) {
+	checkLabels(c.metric, c.labels, values)
+	if delta < 0 {
+		panic("metrics: counter " + c.metric + " cannot decrease")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := seriesKey(values)
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{values: slices.Clone(values)}
+		c.series[key] = s
+	}
+	s.count += delta
+}
+
+func (c *CounterVec) name() string {
+	return c.metric
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	header(w, c.metric, c.help, "counter")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		sample(w, c.metric, s.count, pairs(c.labels, s.values)...)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// Gauge is a single value that can go up and down.
+type Gauge struct {
+	metric string
+	help   string
+	bits   atomic.Uint64
+}
+
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{metric: name, help: help}
+	register(g)
+	return g
+}
+
+func (g *Gauge) Set(v float64) {
+	g.bits.Store(math.Float64bits(v))
+}
+
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+func (g *Gauge) name() string {
+	return g.metric
+}
+
+func (g *Gauge) write(w io.Writer) {
+	header(w, g.metric, g.help, "gauge")
+	sample(w, g.metric, g.Value())
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// DefBuckets suit request latencies in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// ExponentialBuckets returns count upper bounds starting at start, each
+// factor times the last.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// HistogramVec is a family of histograms partitioned by label values.
+type HistogramVec struct {
+	metric  string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	// counts holds the observations falling in each bucket, not the
+	// cumulative counts that are exposed, with the last for +Inf.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	h := &HistogramVec{metric: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogramSeries)}
+	register(h)
+	return h
+}
+
+// Observe records v in the histogram identified by values.
+func (h *HistogramVec) Observe(v float64, values ...string) {
+	checkLabels(h.metric, h.labels, values)
+	i := sort.SearchFloat64s(h.buckets, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := seriesKey(values)
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{values: slices.Clone(values), counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = s
+	}
+	s.counts[i]++
+	s.sum += v
+	s.count++
+}
+
+func (h *HistogramVec) name() string {
+	return h.metric
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	header(w, h.metric, h.help, "histogram")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		labels := pairs(h.labels, s.values)
+
+		var cumulative uint64
+		for i, count := range s.counts {
+			cumulative += count
+			bound := math.Inf(1)
+			if i < len(h.buckets) {
+				bound = h.buckets[i]
+			}
+			le := append(slices.Clip(labels), "le", formatFloat(bound))
+			sample(w, h.metric+"_bucket", float64(cumulative), le...)
+		}
+		sample(w, h.metric+"_sum", s.sum, labels...)
+		sample(w, h.metric+"_count", float64(s.count), labels...)
+	}
+}
+
+// Histogram is a histogram without labels.
+type Histogram struct {
+	vec *HistogramVec
+}
+
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return &Histogram{vec: NewHistogramVec(name, help, buckets)}
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.vec.Observe(v)
+}
@@ -0,0 +1,129 @@
+// Package metrics keeps counters, gauges and histograms in memory and
+// exposes them in the Prometheus text format. Metrics are declared as
+// package-level variables where they are recorded and register themselves.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// collector writes one metric family in the text exposition format.
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+var registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+func register(c collector) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for _, existing := range registry.collectors {
+		if existing.name() == c.name() {
+			panic(fmt.Sprintf("metric %s registered twice", c.name()))
+		}
+	}
+	registry.collectors = append(registry.collectors, c)
+}
+
+// Write renders every registered metric, sorted by name.
+func Write(w io.Writer) error {
+	registry.mu.Lock()
+	collectors := slices.Clone(registry.collectors)
+	registry.mu.Unlock()
+
+	slices.SortFunc(collectors, func(a, b collector) int {
+		return strings.Compare(a.name(), b.name())
+	})
+
+	buf := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buf)
+	}
+	return buf.Flush()
+}
+
+// Handler serves the registered metrics for Prometheus to scrape.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := Write(w); err != nil {
+			log.Printf("error writing metrics: %v", err)
+		}
+	})
+}
+
+// header writes the HELP and TYPE lines that open a metric family.
+func header(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, helpEscaper.Replace(help), name, kind)
+}
+
+// sample writes a single line. labels alternates names and values.
+func sample(w io.Writer, name string, value float64, labels ...string) {
+	io.WriteString(w, name)
+	if len(labels) > 0 {
+		io.WriteString(w, "{")
+		for i := 0; i < len(labels); i += 2 {
+			if i > 0 {
+				io.WriteString(w, ",")
+			}
+			fmt.Fprintf(w, `%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		io.WriteString(w, "}")
+	}
+	fmt.Fprintf(w, " %s\n", formatFloat(value))
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// pairs interleaves label names with their values for sample.
+func pairs(names, values []string) []string {
+	labels := make([]string, 0, 2*len(names))
+	for i, name := range names {
+		labels = append(labels, name, values[i])
+	}
+	return labels
+}
+
+// seriesKey joins label values into a map key. The separator cannot appear
+// in well-formed UTF-8 label values.
+func seriesKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// checkLabels panics when a metric is recorded with the wrong number of
+// label values, which is a programming error.
+func checkLabels(name string, names, values []string) {
+	if len(names) != len(values) {
+		panic(fmt.Sprintf("metric %s wants %d label values, got %d", name, len(names), len(values)))
+	}
+}
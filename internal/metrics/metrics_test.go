@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCounterVecWrite(t *testing.T) {
+	c := NewCounterVec("test_requests_total", "Requests handled.\nBy path.", "path", "code")
+	c.Inc("/b", "200")
+	c.Add(2, "/a", "404")
+	c.Inc("/b", "200")
+	c.Inc(`say "hi"`+"\n"+`\o/`, "200")
+
+	want := `# HELP test_requests_total Requests handled.\nBy path.
+# TYPE test_requests_total counter
+test_requests_total{path="/a",code="404"} 2
+test_requests_total{path="/b",code="200"} 2
+test_requests_total{path="say \"hi\"\n\\o/",code="200"} 1
+`
+	var buf bytes.Buffer
+	c.write(&buf)
+	if got := buf.String(); got != want {
+		t.Errorf("write:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHistogramVecWrite(t *testing.T) {
+	h := NewHistogramVec("test_duration_seconds", "Time taken.", []float64{5, 1, 2.5}, "route")
+	for _, v := range []float64{0.5, 1, 3, 7} {
+		h.Observe(v, "/")
+	}
+	h.Observe(0.25, `a"b`)
+
+	want := `# HELP test_duration_seconds Time taken.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/",le="1"} 2
+test_duration_seconds_bucket{route="/",le="2.5"} 2
+test_duration_seconds_bucket{route="/",le="5"} 3
+test_duration_seconds_bucket{route="/",le="+Inf"} 4
+test_duration_seconds_sum{route="/"} 11.5
+test_duration_seconds_count{route="/"} 4
+test_duration_seconds_bucket{route="a\"b",le="1"} 1
+test_duration_seconds_bucket{route="a\"b",le="2.5"} 1
+test_duration_seconds_bucket{route="a\"b",le="5"} 1
+test_duration_seconds_bucket{route="a\"b",le="+Inf"} 1
+test_duration_seconds_sum{route="a\"b"} 0.25
+test_duration_seconds_count{route="a\"b"} 1
+`
+	var buf bytes.Buffer
+	h.write(&buf)
+	if got := buf.String(); got != want {
+		t.Errorf("write:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteSortsFamilies(t *testing.T) {
+	NewGauge("test_zeta", "Last.").Set(-1.5)
+	NewHistogram("test_alpha_seconds", "First.", []float64{1}).Observe(2)
+
+	var buf bytes.Buffer
+	if err := Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+
+	alpha := `# HELP test_alpha_seconds First.
+# TYPE test_alpha_seconds histogram
+test_alpha_seconds_bucket{le="1"} 0
+test_alpha_seconds_bucket{le="+Inf"} 1
+test_alpha_seconds_sum 2
+test_alpha_seconds_count 1
+`
+	zeta := `# HELP test_zeta Last.
+# TYPE test_zeta gauge
+test_zeta -1.5
+`
+	a, z := strings.Index(got, alpha), strings.Index(got, zeta)
+	if a < 0 || z < 0 {
+		t.Fatalf("Write is missing a family:\n%s", got)
+	}
+	if a > z {
+		t.Errorf("test_alpha_seconds written after test_zeta")
+	}
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	NewGauge("test_twice", "Once.")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate name did not panic")
+		}
+	}()
+	NewGauge("test_twice", "Twice.")
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var startTime = time.Now()
+
+func init() {
+	register(runtimeCollector{})
+}
+
+// runtimeCollector reports Go runtime statistics, read once per scrape.
+type runtimeCollector struct{}
+
+// memStatsMu serialises scrapes so concurrent ones don't each stop the world.
+var memStatsMu sync.Mutex
+
+func (runtimeCollector) name() string {
+	return "go_"
+}
+
+func (runtimeCollector) write(w io.Writer) {
+	memStatsMu.Lock()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	memStatsMu.Unlock()
+
+	header(w, "go_info", "Information about the Go environment.", "gauge")
+	sample(w, "go_info", 1, "version", runtime.Version())
+
+	header(w, "go_goroutines", "Number of goroutines that currently exist.", "gauge")
+	sample(w, "go_goroutines", float64(runtime.NumGoroutine()))
+
+	header(w, "go_gomaxprocs", "Value of GOMAXPROCS.", "gauge")
+	sample(w, "go_gomaxprocs", float64(runtime.GOMAXPROCS(0)))
+
+	for _, m := range []struct {
+		name, help string
+		value      uint64
+	}{
+		{"go_memstats_alloc_bytes", "Bytes of allocated heap objects.", stats.Alloc},
+		{"go_memstats_heap_inuse_bytes", "Bytes in in-use heap spans.", stats.HeapInuse},
+		{"go_memstats_heap_objects", "Number of allocated heap objects.", stats.HeapObjects},
+		{"go_memstats_stack_inuse_bytes", "Bytes in stack spans.", stats.StackInuse},
+		{"go_memstats_sys_bytes", "Bytes of memory obtained from the OS.", stats.Sys},
+		{"go_memstats_next_gc_bytes", "Heap size at which the next GC cycle starts.", stats.NextGC},
+	} {
+		header(w, m.name, m.help, "gauge")
+		sample(w, m.name, float64(m.value))
+	}
+
+	header(w, "go_memstats_alloc_bytes_total", "Cumulative bytes allocated for heap objects.", "counter")
+	sample(w, "go_memstats_alloc_bytes_total", float64(stats.TotalAlloc))
+
+	header(w, "go_gc_cycles_total", "Number of completed GC cycles.", "counter")
+	sample(w, "go_gc_cycles_total", float64(stats.NumGC))
+
+	header(w, "go_gc_pause_seconds_total", "Cumulative time spent in GC stop-the-world pauses.", "counter")
+	sample(w, "go_gc_pause_seconds_total", float64(stats.PauseTotalNs)/1e9)
+
+	header(w, "process_start_time_seconds", "Start time of the process since the Unix epoch in seconds.", "gauge")
+	sample(w, "process_start_time_seconds", float64(startTime.UnixNano())/1e9)
+}
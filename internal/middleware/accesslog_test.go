@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.1, fd00::/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		want         string
+	}{
+		{"direct client", "203.0.113.9:1234", nil, "203.0.113.9"},
+		{"untrusted peer cannot forward", "203.0.113.9:1234", []string{"198.51.100.1"}, "203.0.113.9"},
+		{"trusted proxy", "10.0.0.2:80", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"trusted proxy without header", "10.0.0.2:80", nil, "10.0.0.2"},
+		{"trusted proxy with empty header", "10.0.0.2:80", []string{""}, "10.0.0.2"},
+		{"chain of trusted proxies", "10.0.0.2:80", []string{"198.51.100.1, 192.168.1.1, 10.1.1.1"}, "198.51.100.1"},
+		{"spoofed entries left of the client are ignored", "10.0.0.2:80", []string{"1.1.1.1, 198.51.100.1"}, "198.51.100.1"},
+		{"multiple headers", "10.0.0.2:80", []string{"1.1.1.1", "198.51.100.1, 10.1.1.1"}, "198.51.100.1"},
+		{"every hop trusted", "10.0.0.2:80", []string{"10.3.3.3, 10.1.1.1"}, "10.3.3.3"},
+		{"hop with port", "10.0.0.2:80", []string{"198.51.100.1:5555"}, "198.51.100.1"},
+		{"ipv6 hop with port", "10.0.0.2:80", []string{"[2001:db8::1]:443"}, "2001:db8::1"},
+		{"malformed hop stops at the last proxy", "10.0.0.2:80", []string{"198.51.100.1, nonsense"}, "10.0.0.2"},
+		{"ipv4-mapped hop", "10.0.0.2:80", []string{"::ffff:198.51.100.1"}, "198.51.100.1"},
+		{"ipv6 trusted proxy", "[fd00::1]:80", []string{"2001:db8::2"}, "2001:db8::2"},
+		{"ipv6 untrusted peer", "[2001:db8::3]:80", []string{"198.51.100.1"}, "2001:db8::3"},
+		{"remote addr without port", "10.0.0.2", []string{"198.51.100.1"}, "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			if got := clientIP(r, trusted); got != tt.want {
+				t.Errorf("clientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPTrustsNoOneByDefault(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "127.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	if got := clientIP(r, nil); got != "127.0.0.1" {
+		t.Errorf("clientIP = %q, want the peer address", got)
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{" , ", nil, false},
+		{"10.0.0.1", []string{"10.0.0.1/32"}, false},
+		{"10.1.2.3/8", []string{"10.0.0.0/8"}, false},
+		{"::ffff:10.0.0.1", []string{"10.0.0.1/32"}, false},
+		{"fd00::1, 192.168.0.0/16", []string{"fd00::1/128", "192.168.0.0/16"}, false},
+		{"10.0.0.300", nil, true},
+		{"10.0.0.0/33", nil, true},
+		{"proxy.internal", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTrustedProxies(tt.list)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTrustedProxies(%q) error = %v, wantErr %v", tt.list, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseTrustedProxies(%q) = %v, want %v", tt.list, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i].String() != tt.want[i] {
+				t.Errorf("ParseTrustedProxies(%q) = %v, want %v", tt.list, got, tt.want)
+				break
+			}
+		}
+	}
+}
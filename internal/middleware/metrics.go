@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"jordanmurray.xyz/site/internal/metrics"
+)
+
+var (
+	requests = metrics.NewCounterVec("site_http_requests_total",
+		"HTTP requests by route pattern, method and status code.",
+		"route", "method", "code")
+	requestDuration = metrics.NewHistogramVec("site_http_request_duration_seconds",
+		"Time taken to serve HTTP requests by route pattern.",
+		metrics.DefBuckets, "route")
+	responseSize = metrics.NewHistogramVec("site_http_response_size_bytes",
+		"Size of HTTP response bodies by route pattern and content encoding.",
+		metrics.ExponentialBuckets(256, 4, 8), "route", "encoding")
+)
+
+// Metrics records request counts, latencies and response sizes. It must sit
+// directly in front of the ServeMux, which sets the route pattern on the
+// request it is handed.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &recorder{ResponseWriter: w}
+
+		next.ServeHTTP(rec, r)
+		rec.finish(r)
+
+		route := routeLabel(r.Pattern)
+		requests.Inc(route, methodLabel(r.Method), strconv.Itoa(rec.status))
+		requestDuration.Observe(time.Since(start).Seconds(), route)
+		responseSize.Observe(float64(rec.bytes), route, rec.encoding)
+	})
+}
+
+// routeLabel drops the method from a pattern. Requests that matched no
+// route share one label so arbitrary paths cannot create new series.
+func routeLabel(pattern string) string {
+	if pattern == "" {
+		return "unmatched"
+	}
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+	return pattern
+}
+
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "other"
+}
@@ -0,0 +1,17 @@
+// Package middleware wraps the site's handlers with per-request concerns
+// such as request IDs and access logging.
+package middleware
+
+import "net/http"
+
+// Middleware decorates a handler.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps handler in middlewares so that the first one listed sees the
+// request first.
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
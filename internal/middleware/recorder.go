@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+
+	"jordanmurray.xyz/site/internal/utils"
+)
+
+// recorder notes what a handler wrote. The encoding is read from the
+// Content-Encoding header renderer.Write sets before the body is sent.
+type recorder struct {
+	http.ResponseWriter
+	status   int
+	bytes    int64
+	encoding string
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+		rec.encoding = rec.Header().Get("Content-Encoding")
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Flush passes through so streamed responses are not buffered.
+func (rec *recorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rec *recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// finish fills in what a handler left implicit once it has returned.
+func (rec *recorder) finish(r *http.Request) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if rec.encoding == "" {
+		rec.encoding = string(utils.Identity)
+	}
+	// net/http discards whatever a handler writes for HEAD.
+	if r.Method == http.MethodHead {
+		rec.bytes = 0
+	}
+}
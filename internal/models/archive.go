@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type ArchiveMonth struct {
+	Year  int
+	Month time.Month
+	Posts []Post
+}
+
+type ArchiveYear struct {
+	Year   int
+	Months []ArchiveMonth
+	Posts  []Post
+}
+
+// GroupByMonth buckets posts by the year and month they were published,
+// keeping the order of posts, which callers sort newest first.
+func GroupByMonth(posts []Post) []ArchiveYear {
+	var years []ArchiveYear
+
+	for _, post := range posts {
+		year, month := post.PublishedAt.Year(), post.PublishedAt.Month()
+
+		if len(years) == 0 || years[len(years)-1].Year != year {
+			years = append(years, ArchiveYear{Year: year})
+		}
+		y := &years[len(years)-1]
+		y.Posts = append(y.Posts, post)
+
+		if len(y.Months) == 0 || y.Months[len(y.Months)-1].Month != month {
+			y.Months = append(y.Months, ArchiveMonth{Year: year, Month: month})
+		}
+		m := &y.Months[len(y.Months)-1]
+		m.Posts = append(m.Posts, post)
+	}
+
+	return years
+}
+
+func YearURL(year int) string {
+	return fmt.Sprintf("/reflections/%d", year)
+}
+
+func MonthURL(year int, month time.Month) string {
+	return fmt.Sprintf("/reflections/%d/%02d", year, int(month))
+}
+
+func (y ArchiveYear) URL() string {
+	return YearURL(y.Year)
+}
+
+func (m ArchiveMonth) URL() string {
+	return MonthURL(m.Year, m.Month)
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Author   *atomAuthor `xml:"author,omitempty"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary"`
+	Content    atomContent    `xml:"content"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type AtomFeed struct {
+	RSSConfig
+	Feed    []byte
+	Updated time.Time
+}
+
+func NewAtomFeed(cfg RSSConfig) AtomFeed {
+	return AtomFeed{
+		RSSConfig: cfg,
+	}
+}
+
+func (a *AtomFeed) FromPosts(posts []Post) error {
+	var entries []atomEntry
+	var updated time.Time
+
+	for _, post := range posts {
+		postPath := a.postURL(post.Slug)
+		categories := make([]atomCategory, len(post.Tags))
+		for i, tag := range post.Tags {
+			categories[i] = atomCategory{Term: tag}
+		}
+
+		var author *atomAuthor
+		if post.Author != "" {
+			author = &atomAuthor{Name: post.Author}
+		}
+
+		entries = append(entries, atomEntry{
+			Title:      post.Title,
+			ID:         postPath,
+			Links:      []atomLink{{Href: postPath, Rel: "alternate", Type: "text/html"}},
+			Published:  post.PublishedAt.Format(time.RFC3339),
+			Updated:    post.Modified().Format(time.RFC3339),
+			Summary:    post.Excerpt,
+			Content:    atomContent{Type: "html", Body: a.absoluteContent(post)},
+			Author:     author,
+			Categories: categories,
+		})
+
+		if post.Modified().After(updated) {
+			updated = post.Modified()
+		}
+	}
+
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	// Entries without an author inherit the feed's, which RFC 4287 requires
+	// unless every entry names one.
+	var author *atomAuthor
+	if a.Author != "" {
+		author = &atomAuthor{Name: a.Author}
+	}
+
+	feed := atomFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		Title:    a.Title,
+		Subtitle: a.Description,
+		ID:       a.BaseURL + "/",
+		Updated:  updated.Format(time.RFC3339),
+		Author:   author,
+		Links: []atomLink{
+			{Href: a.feedURL("feed.atom"), Rel: "self", Type: "application/atom+xml"},
+			{Href: a.BaseURL, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: entries,
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(feed); err != nil {
+		return fmt.Errorf("failed to encode atom feed: %w", err)
+	}
+
+	a.Feed = buf.Bytes()
+	a.Updated = updated
+
+	return nil
+}
+
+func (a AtomFeed) Empty() bool {
+	return len(a.Feed) == 0
+}
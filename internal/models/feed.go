@@ -0,0 +1,36 @@
+package models
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var urlAttrPattern = regexp.MustCompile(`\b(href|src)="([^"]*)"`)
+
+func (c RSSConfig) postURL(slug string) string {
+	return strings.Join([]string{c.BaseURL, "reflections", slug}, "/")
+}
+
+func (c RSSConfig) feedURL(name string) string {
+	return strings.Join([]string{c.BaseURL, "reflections", name}, "/")
+}
+
+// absoluteContent rewrites relative href and src attributes in rendered post
+// HTML so they resolve against the post's permalink when read in a feed reader.
+func (c RSSConfig) absoluteContent(post Post) string {
+	base, err := url.Parse(c.postURL(post.Slug))
+	if err != nil {
+		return post.Content
+	}
+
+	return urlAttrPattern.ReplaceAllStringFunc(post.Content, func(attr string) string {
+		match := urlAttrPattern.FindStringSubmatch(attr)
+		ref, err := url.Parse(match[2])
+		if err != nil || ref.IsAbs() || strings.HasPrefix(match[2], "//") {
+			return attr
+		}
+
+		return match[1] + `="` + base.ResolveReference(ref).String() + `"`
+	})
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type JSONFeed struct {
+	RSSConfig
+	Feed    []byte
+	Updated time.Time
+}
+
+func NewJSONFeed(cfg RSSConfig) JSONFeed {
+	return JSONFeed{
+		RSSConfig: cfg,
+	}
+}
+
+func (j *JSONFeed) FromPosts(posts []Post) error {
+	items := make([]jsonFeedItem, 0, len(posts))
+	var updated time.Time
+
+	for _, post := range posts {
+		postPath := j.postURL(post.Slug)
+		item := jsonFeedItem{
+			ID:            postPath,
+			URL:           postPath,
+			Title:         post.Title,
+			ContentHTML:   j.absoluteContent(post),
+			Summary:       post.Excerpt,
+			DatePublished: post.PublishedAt.Format(time.RFC3339),
+			Tags:          post.Tags,
+		}
+		if post.Revised() {
+			item.DateModified = post.UpdatedAt.Format(time.RFC3339)
+		}
+		if post.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: post.Author}}
+		}
+		items = append(items, item)
+
+		if post.Modified().After(updated) {
+			updated = post.Modified()
+		}
+	}
+
+	feed := jsonFeed{
+		Version:     jsonFeedVersion,
+		Title:       j.Title,
+		HomePageURL: j.BaseURL,
+		FeedURL:     j.feedURL("feed.json"),
+		Description: j.Description,
+		Language:    "en-us",
+		Items:       items,
+	}
+
+	data, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode json feed: %w", err)
+	}
+
+	j.Feed = data
+	j.Updated = updated
+
+	return nil
+}
+
+func (j JSONFeed) Empty() bool {
+	return len(j.Feed) == 0
+}
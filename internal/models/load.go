@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Source is a loaded post along with the file it came from.
+type Source struct {
+	Path string
+	// Markdown is the body following the front matter.
+	Markdown []byte
+	// LineOffset is the number of lines preceding Markdown in the file.
+	LineOffset int
+	Post
+}
+
+// LoadPosts loads every post under reflections/. Problems with individual
+// posts do not stop the walk; they are collected and returned alongside the
+// posts that did load. The error is reserved for failures walking the tree.
+func LoadPosts(fsys fs.FS) ([]Source, Problems, error) {
+	var sources []Source
+	var problems Problems
+	pathBySlug := make(map[string]string)
+
+	err := fs.WalkDir(fsys, "reflections", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("walking error: %w", err)
+		}
+
+		if d.IsDir() || filepath.Ext(d.Name()) != ".md" {
+			return nil
+		}
+
+		src, err := loadSource(fsys, path)
+		if err != nil {
+			problems = append(problems, AsProblems(path, err)...)
+			return nil
+		}
+
+		if err := ValidateSlug(src.Slug); err != nil {
+			problems = append(problems, Problem{Path: path, Message: err.Error()})
+			return nil
+		}
+
+		if other, ok := pathBySlug[src.Slug]; ok {
+			problems = append(problems, Problem{
+				Path:    path,
+				Message: fmt.Sprintf("duplicate slug %q, also used by %s", src.Slug, other),
+			})
+			return nil
+		}
+		pathBySlug[src.Slug] = path
+
+		sources = append(sources, src)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sources, problems, nil
+}
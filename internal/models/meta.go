@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+)
+
+// PageMeta describes a page for link previews and search engines.
+type PageMeta struct {
+	Title       string
+	Description string
+	// Path is the page's path on the site. Pages without one get no
+	// canonical URL.
+	Path string
+	// Image is the path of the preview image; empty uses the site default.
+	Image string
+	// Type is the Open Graph type, "website" unless set.
+	Type        string
+	Author      string
+	PublishedAt time.Time
+	UpdatedAt   time.Time
+	Tags        []string
+}
+
+// PostMeta describes a reflection from its front matter.
+func PostMeta(post Post) PageMeta {
+	return PageMeta{
+		Title:       post.Title,
+		Description: post.Excerpt,
+		Path:        "/reflections/" + post.Slug,
+		Image:       "/reflections/" + post.Slug + "/og.png",
+		Type:        "article",
+		Author:      post.Author,
+		PublishedAt: post.PublishedAt,
+		UpdatedAt:   post.Modified(),
+		Tags:        post.Tags,
+	}
+}
+
+// Article reports whether the page is a single piece of writing rather than
+// a listing.
+func (m PageMeta) Article() bool {
+	return m.Type == "article"
+}
+
+// BlogPosting is the schema.org structured data for a reflection.
+type BlogPosting struct {
+	Context          string       `json:"@context"`
+	Type             string       `json:"@type"`
+	Headline         string       `json:"headline"`
+	Description      string       `json:"description,omitempty"`
+	URL              string       `json:"url"`
+	MainEntityOfPage string       `json:"mainEntityOfPage"`
+	Image            string       `json:"image,omitempty"`
+	DatePublished    string       `json:"datePublished"`
+	DateModified     string       `json:"dateModified"`
+	Keywords         []string     `json:"keywords,omitempty"`
+	Author           schemaPerson `json:"author"`
+}
+
+type schemaPerson struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// BlogPosting returns the structured data for the page, given its absolute
+// URL and image URL.
+func (m PageMeta) BlogPosting(url, image string) BlogPosting {
+	return BlogPosting{
+		Context:          "https://schema.org",
+		Type:             "BlogPosting",
+		Headline:         m.Title,
+		Description:      m.Description,
+		URL:              url,
+		MainEntityOfPage: url,
+		Image:            image,
+		DatePublished:    m.PublishedAt.Format(time.RFC3339),
+		DateModified:     m.UpdatedAt.Format(time.RFC3339),
+		Keywords:         m.Tags,
+		Author:           schemaPerson{Type: "Person", Name: m.Author},
+	}
+}
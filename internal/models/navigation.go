@@ -0,0 +1,28 @@
+package models
+
+// Navigation links a reflection to its chronological neighbours and to the
+// reflections most related to it.
+type Navigation struct {
+	// Previous is the next older post, if any.
+	Previous *Post
+	// Next is the next newer post, if any.
+	Next    *Post
+	Related []Post
+}
+
+// Navigate returns the navigation for each post, keyed by slug. posts must be
+// sorted newest first; related supplies the related posts for each slug.
+func Navigate(posts []Post, related map[string][]Post) map[string]Navigation {
+	navigation := make(map[string]Navigation, len(posts))
+	for i, post := range posts {
+		nav := Navigation{Related: related[post.Slug]}
+		if i+1 < len(posts) {
+			nav.Previous = &posts[i+1]
+		}
+		if i > 0 {
+			nav.Next = &posts[i-1]
+		}
+		navigation[post.Slug] = nav
+	}
+	return navigation
+}
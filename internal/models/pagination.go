@@ -0,0 +1,59 @@
+package models
+
+import "fmt"
+
+const DefaultPageSize = 10
+
+// Page is one page of the reflections index.
+type Page struct {
+	Number int
+	Total  int
+	Posts  []Post
+}
+
+// Paginate splits posts into pages of size posts. There is always at least
+// one page so that an empty archive still renders an index.
+func Paginate(posts []Post, size int) []Page {
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+
+	total := max(1, (len(posts)+size-1)/size)
+	pages := make([]Page, total)
+
+	for i := range pages {
+		start := i * size
+		end := min(start+size, len(posts))
+		pages[i] = Page{
+			Number: i + 1,
+			Total:  total,
+			Posts:  posts[start:end],
+		}
+	}
+
+	return pages
+}
+
+// PageURL returns the path of the nth page of the reflections index.
+func PageURL(n int) string {
+	if n <= 1 {
+		return "/reflections"
+	}
+	return fmt.Sprintf("/reflections/page/%d", n)
+}
+
+func (p Page) HasPrev() bool {
+	return p.Number > 1
+}
+
+func (p Page) HasNext() bool {
+	return p.Number < p.Total
+}
+
+func (p Page) PrevURL() string {
+	return PageURL(p.Number - 1)
+}
+
+func (p Page) NextURL() string {
+	return PageURL(p.Number + 1)
+}
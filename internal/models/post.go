@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
@@ -13,7 +14,10 @@ import (
 	highlighting "github.com/yuin/goldmark-highlighting/v2"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
 	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,6 +25,13 @@ type Post struct {
 	ID      string
 	Slug    string
 	Content string
+	// PageContent is Content with a hover anchor on each heading, for the
+	// post's own page.
+	PageContent string
+	// TOC is the tree of headings in Content.
+	TOC []Heading
+	// WordCount is the number of words in the markdown source.
+	WordCount int
 	FrontMatter
 }
 
@@ -28,31 +39,123 @@ type FrontMatter struct {
 	Title       string    `yaml:"title"`
 	Author      string    `yaml:"author"`
 	PublishedAt time.Time `yaml:"published_at"`
+	UpdatedAt   time.Time `yaml:"updated_at"`
 	Excerpt     string    `yaml:"excerpt"`
 	Tags        []string  `yaml:"tags"`
+	Draft       bool      `yaml:"draft"`
+	// ShowTOC displays the table of contents alongside the post.
+	ShowTOC bool `yaml:"toc"`
 }
 
-func parseFrontMatter(content []byte) (FrontMatter, []byte, error) {
+// Visible reports whether the post should be served to readers at now:
+// drafts are never visible and scheduled posts wait for their published_at.
+func (p Post) Visible(now time.Time) bool {
+	return !p.Draft && !p.PublishedAt.After(now)
+}
+
+func parseFrontMatter(path string, content []byte) (FrontMatter, []byte, error) {
 	var fm FrontMatter
 
 	if !bytes.HasPrefix(content, []byte("---\n")) {
-		return fm, content, fmt.Errorf("no front matter found")
+		return fm, content, Problem{Path: path, Line: 1, Message: "no front matter found"}
 	}
 
 	parts := bytes.SplitN(content[4:], []byte("\n---\n"), 2)
 	if len(parts) != 2 {
-		return fm, content, fmt.Errorf("invalid front matter format")
+		return fm, content, Problem{Path: path, Line: 1, Message: "invalid front matter format"}
 	}
 
-	if err := yaml.Unmarshal(parts[0], &fm); err != nil {
-		return fm, []byte{}, fmt.Errorf("failed to parse front matter: %w", err)
+	fm, problems := decodeFrontMatter(path, parts[0])
+	if len(problems) > 0 {
+		return fm, []byte{}, problems
 	}
 
 	return fm, parts[1], nil
 }
 
-func renderMarkdown(markdown []byte) ([]byte, error) {
-	md := goldmark.New(
+// frontMatterKeys are the keys FrontMatter's yaml tags accept.
+var frontMatterKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeFor[FrontMatter]()
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
+// decodeFrontMatter decodes each front matter key separately so that every
+// bad value is reported with the line it appears on.
+func decodeFrontMatter(path string, raw []byte) (FrontMatter, Problems) {
+	var fm FrontMatter
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return fm, frontMatterProblems(path, err)
+	}
+
+	if len(doc.Content) == 0 {
+		return fm, nil
+	}
+
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return fm, Problems{{Path: path, Line: mapping.Line + 1, Message: "front matter must be a mapping"}}
+	}
+
+	var problems Problems
+	invalid := make(map[string]bool)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if !frontMatterKeys[key.Value] {
+			problems = append(problems, Problem{
+				Path:    path,
+				Line:    key.Line + 1,
+				Message: fmt.Sprintf("unknown front matter key %q", key.Value),
+			})
+			continue
+		}
+
+		field := yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}}
+
+		if err := field.Decode(&fm); err != nil {
+			invalid[key.Value] = true
+			problems = append(problems, Problem{
+				Path:    path,
+				Line:    value.Line + 1,
+				Message: fmt.Sprintf("invalid %s: %s", key.Value, yamlMessage(err)),
+			})
+		}
+	}
+
+	return fm, append(problems, fm.validate(path, invalid)...)
+}
+
+// renderMarkdown renders markdown twice from one parse: plainly, for feeds
+// and search, and with heading anchors for the post's page.
+func renderMarkdown(markdown []byte) (content, pageContent []byte, toc []Heading, err error) {
+	md := newMarkdown()
+	page := newMarkdown(goldmark.WithRendererOptions(
+		renderer.WithNodeRenderers(util.Prioritized(anchoredHeadings{}, 100)),
+	))
+
+	doc := md.Parser().Parse(text.NewReader(markdown))
+	toc = tableOfContents(doc, markdown)
+
+	var buf, pageBuf bytes.Buffer
+	if err := md.Renderer().Render(&buf, markdown, doc); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to render markdown: %w", err)
+	}
+	if err := page.Renderer().Render(&pageBuf, markdown, doc); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	return buf.Bytes(), pageBuf.Bytes(), toc, nil
+}
+
+func newMarkdown(options ...goldmark.Option) goldmark.Markdown {
+	return goldmark.New(append([]goldmark.Option{
 		goldmark.WithExtensions(
 			extension.GFM,
 			extension.Typographer,
@@ -70,45 +173,64 @@ func renderMarkdown(markdown []byte) ([]byte, error) {
 		goldmark.WithRendererOptions(
 			goldmarkhtml.WithUnsafe(),
 		),
-	)
+	}, options...)...)
+}
 
-	var buf bytes.Buffer
-	if err := md.Convert(markdown, &buf); err != nil {
-		return []byte{}, fmt.Errorf("failed to render markdown: %w", err)
+func LoadPostFromFS(fsys fs.FS, path string) (Post, error) {
+	src, err := loadSource(fsys, path)
+	if err != nil {
+		return Post{}, err
 	}
 
-	return buf.Bytes(), nil
+	return src.Post, nil
 }
 
-func LoadPostFromFS(fsys fs.FS, path string) (Post, error) {
+// loadSource reads and renders the post at path, keeping the markdown body
+// it was rendered from.
+func loadSource(fsys fs.FS, path string) (Source, error) {
 	content, err := fs.ReadFile(fsys, path)
 	if err != nil {
-		return Post{}, fmt.Errorf("failed to read file: %w", err)
+		return Source{}, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	fm, markdown, err := parseFrontMatter(content)
+	fm, markdown, err := parseFrontMatter(path, content)
 	if err != nil {
-		return Post{}, fmt.Errorf("failed to parse front matter: %w", err)
+		return Source{}, err
 	}
 
-	htmlContent, err := renderMarkdown(markdown)
+	htmlContent, pageContent, toc, err := renderMarkdown(markdown)
 	if err != nil {
-		return Post{}, fmt.Errorf("failed to render markdown: %w", err)
+		return Source{}, fmt.Errorf("failed to render markdown: %w", err)
 	}
 
 	filename := filepath.Base(path)
 	slug := strings.TrimSuffix(filename, filepath.Ext(filename))
 
-	return Post{
-		ID: slug,
-		FrontMatter: FrontMatter{
-			Title:       fm.Title,
-			Author:      fm.Author,
-			PublishedAt: fm.PublishedAt,
-			Excerpt:     fm.Excerpt,
-			Tags:        fm.Tags,
+	// markdown is the tail of content, so whatever precedes it is the
+	// front matter.
+	frontMatter := content[:len(content)-len(markdown)]
+
+	return Source{
+		Path:       path,
+		Markdown:   markdown,
+		LineOffset: bytes.Count(frontMatter, []byte("\n")),
+		Post: Post{
+			ID: slug,
+			FrontMatter: FrontMatter{
+				Title:       fm.Title,
+				Author:      fm.Author,
+				PublishedAt: fm.PublishedAt,
+				UpdatedAt:   fm.UpdatedAt,
+				Excerpt:     fm.Excerpt,
+				Tags:        fm.Tags,
+				Draft:       fm.Draft,
+				ShowTOC:     fm.ShowTOC,
+			},
+			Slug:        slug,
+			Content:     string(htmlContent),
+			PageContent: string(pageContent),
+			TOC:         toc,
+			WordCount:   countWords(markdown),
 		},
-		Slug:    slug,
-		Content: string(htmlContent),
 	}, nil
 }
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+): (.*)$`)
+
+// Problem is a single issue found in a content file.
+type Problem struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (p Problem) Error() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", p.Path, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.Path, p.Message)
+}
+
+// Problems aggregates every issue found across a content tree so they can
+// be reported together rather than one at a time.
+type Problems []Problem
+
+func (p Problems) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d content problem(s):", len(p))
+	for _, problem := range p {
+		b.WriteString("\n  ")
+		b.WriteString(problem.Error())
+	}
+	return b.String()
+}
+
+// AsProblems flattens err into Problems, attributing anything that is not
+// already a Problem to path.
+func AsProblems(path string, err error) Problems {
+	var problems Problems
+	if errors.As(err, &problems) {
+		return problems
+	}
+
+	var problem Problem
+	if errors.As(err, &problem) {
+		return Problems{problem}
+	}
+
+	return Problems{{Path: path, Message: err.Error()}}
+}
+
+// frontMatterProblems converts a yaml syntax error into a Problem with a line
+// number relative to the start of the file rather than the front matter block.
+func frontMatterProblems(path string, err error) Problems {
+	match := yamlLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return Problems{{Path: path, Message: "malformed front matter: " + err.Error()}}
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	return Problems{{
+		Path:    path,
+		Line:    line + 1,
+		Message: "malformed front matter: " + match[2],
+	}}
+}
+
+// yamlMessage strips yaml's own prefixes and line numbers from err, which
+// refer to a single decoded field rather than the file.
+func yamlMessage(err error) string {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		err = errors.New(typeErr.Errors[0])
+	}
+
+	if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+		return match[2]
+	}
+	return err.Error()
+}
+
+// validate reports required keys that are missing, skipping any that were
+// present but already reported as invalid.
+func (fm FrontMatter) validate(path string, invalid map[string]bool) Problems {
+	var problems Problems
+
+	if strings.TrimSpace(fm.Title) == "" && !invalid["title"] {
+		problems = append(problems, Problem{Path: path, Message: "front matter is missing title"})
+	}
+	if fm.PublishedAt.IsZero() && !invalid["published_at"] {
+		problems = append(problems, Problem{Path: path, Message: "front matter is missing published_at"})
+	}
+	if !fm.UpdatedAt.IsZero() && fm.UpdatedAt.Before(fm.PublishedAt) {
+		problems = append(problems, Problem{Path: path, Message: "updated_at is before published_at"})
+	}
+
+	return problems
+}
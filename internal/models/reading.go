@@ -0,0 +1,44 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// wordsPerMinute is the reading speed used to estimate reading time.
+const wordsPerMinute = 200
+
+// ReadingTime estimates how many minutes the post takes to read, rounding up
+// to at least a minute.
+func (p Post) ReadingTime() int {
+	return max(1, (p.WordCount+wordsPerMinute-1)/wordsPerMinute)
+}
+
+// Modified is when the post last changed: its updated_at if set, otherwise
+// when it was published.
+func (p Post) Modified() time.Time {
+	if p.UpdatedAt.After(p.PublishedAt) {
+		return p.UpdatedAt
+	}
+	return p.PublishedAt
+}
+
+// Revised reports whether the post has been updated since it was published.
+func (p Post) Revised() bool {
+	return p.UpdatedAt.After(p.PublishedAt)
+}
+
+// countWords counts the words in markdown source, ignoring tokens such as
+// list markers and heading hashes that contain no letters or digits.
+func countWords(markdown []byte) int {
+	count := 0
+	for _, field := range strings.Fields(string(markdown)) {
+		if strings.IndexFunc(field, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsDigit(r)
+		}) >= 0 {
+			count++
+		}
+	}
+	return count
+}
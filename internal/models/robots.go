@@ -0,0 +1,36 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Robots is a robots.txt that points crawlers at the sitemap.
+type Robots struct {
+	RSSConfig
+	Text []byte
+}
+
+// NewRobots builds a robots.txt disallowing the given path prefixes. With
+// disallowAll set every path is disallowed, for preview deployments that
+// should stay out of search results.
+func NewRobots(cfg RSSConfig, disallow []string, disallowAll bool) Robots {
+	var buf bytes.Buffer
+	buf.WriteString("User-agent: *\n")
+	if disallowAll {
+		buf.WriteString("Disallow: /\n")
+	} else {
+		if len(disallow) == 0 {
+			buf.WriteString("Disallow:\n")
+		}
+		for _, path := range disallow {
+			fmt.Fprintf(&buf, "Disallow: %s\n", path)
+		}
+	}
+	fmt.Fprintf(&buf, "\nSitemap: %s/sitemap.xml\n", cfg.BaseURL)
+
+	return Robots{
+		RSSConfig: cfg,
+		Text:      buf.Bytes(),
+	}
+}
@@ -4,14 +4,15 @@ import (
 	"bytes"
 	"encoding/xml"
 	"fmt"
-	"strings"
 	"time"
 )
 
 type rss struct {
-	XMLName xml.Name `xml:"rss"`
-	Version string   `xml:"version,attr"`
-	Channel channel  `xml:"channel"`
+	XMLName      xml.Name `xml:"rss"`
+	Version      string   `xml:"version,attr"`
+	XmlnsContent string   `xml:"xmlns:content,attr"`
+	XmlnsDC      string   `xml:"xmlns:dc,attr"`
+	Channel      channel  `xml:"channel"`
 }
 
 type channel struct {
@@ -24,22 +25,40 @@ type channel struct {
 }
 
 type item struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
-	GUID        string `xml:"guid"`
+	Title          string `xml:"title"`
+	Link           string `xml:"link"`
+	Description    string `xml:"description"`
+	ContentEncoded cdata  `xml:"content:encoded"`
+	// Creator names the author; RSS's own author element must be an email
+	// address.
+	Creator    string   `xml:"dc:creator,omitempty"`
+	Categories []string `xml:"category"`
+	PubDate    string   `xml:"pubDate"`
+	GUID       guid     `xml:"guid"`
+}
+
+type cdata struct {
+	Text string `xml:",cdata"`
+}
+
+type guid struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
 }
 
 type RSSConfig struct {
 	BaseURL     string
 	Title       string
 	Description string
+	// Author is the site owner, credited by feeds that require an author
+	// for posts which do not name one.
+	Author string
 }
 
 type RSSFeed struct {
 	RSSConfig
-	Feed []byte
+	Feed    []byte
+	Updated time.Time
 }
 
 func NewRSSFeed(cfg RSSConfig) RSSFeed {
@@ -53,17 +72,20 @@ func (r *RSSFeed) FromPosts(posts []Post) error {
 	var lastBuildDate time.Time
 
 	for _, post := range posts {
-		postPath := strings.Join([]string{r.BaseURL, post.Slug}, "/")
+		postPath := r.postURL(post.Slug)
 		items = append(items, item{
-			Title:       post.Title,
-			Link:        postPath,
-			Description: post.Excerpt,
-			PubDate:     post.PublishedAt.Format(time.RFC1123Z),
-			GUID:        postPath,
+			Title:          post.Title,
+			Link:           postPath,
+			Description:    post.Excerpt,
+			ContentEncoded: cdata{Text: r.absoluteContent(post)},
+			Creator:        post.Author,
+			Categories:     post.Tags,
+			PubDate:        post.PublishedAt.Format(time.RFC1123Z),
+			GUID:           guid{Value: postPath, IsPermaLink: true},
 		})
 
-		if post.PublishedAt.After(lastBuildDate) {
-			lastBuildDate = post.PublishedAt
+		if post.Modified().After(lastBuildDate) {
+			lastBuildDate = post.Modified()
 		}
 	}
 
@@ -72,7 +94,9 @@ func (r *RSSFeed) FromPosts(posts []Post) error {
 	}
 
 	feed := rss{
-		Version: "2.0",
+		Version:      "2.0",
+		XmlnsContent: "http://purl.org/rss/1.0/modules/content/",
+		XmlnsDC:      "http://purl.org/dc/elements/1.1/",
 		Channel: channel{
 			Title:         r.Title,
 			Link:          r.BaseURL,
@@ -94,6 +118,7 @@ func (r *RSSFeed) FromPosts(posts []Post) error {
 	}
 
 	r.Feed = buf.Bytes()
+	r.Updated = lastBuildDate
 
 	return nil
 }
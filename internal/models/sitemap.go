@@ -0,0 +1,88 @@
+package models
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type Sitemap struct {
+	RSSConfig
+	XML     []byte
+	Updated time.Time
+}
+
+func NewSitemap(cfg RSSConfig) Sitemap {
+	return Sitemap{
+		RSSConfig: cfg,
+	}
+}
+
+// FromPosts lists the home page, the reflection listings, every post and
+// every tag page, each with the time its content last changed.
+func (s *Sitemap) FromPosts(posts []Post, tags []Tag) error {
+	updated := LatestModified(posts)
+
+	urls := []sitemapURL{
+		s.url("/", updated),
+		s.url("/reflections", updated),
+		s.url("/reflections/archive", updated),
+		s.url("/reflections/tags", updated),
+	}
+	for _, post := range posts {
+		urls = append(urls, s.url("/reflections/"+post.Slug, post.Modified()))
+	}
+	for _, tag := range tags {
+		urls = append(urls, s.url("/reflections/tags/"+tag.Slug, LatestModified(tag.Posts)))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+
+	err := encoder.Encode(urlSet{
+		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  urls,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode sitemap: %w", err)
+	}
+
+	s.XML = buf.Bytes()
+	s.Updated = updated
+
+	return nil
+}
+
+func (s Sitemap) url(path string, modified time.Time) sitemapURL {
+	u := sitemapURL{Loc: s.BaseURL + path}
+	if !modified.IsZero() {
+		u.LastMod = modified.UTC().Format(time.RFC3339)
+	}
+	return u
+}
+
+// LatestModified returns the most recent Modified time of posts.
+func LatestModified(posts []Post) time.Time {
+	var latest time.Time
+	for _, post := range posts {
+		if post.Modified().After(latest) {
+			latest = post.Modified()
+		}
+	}
+	return latest
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reservedReflectionPaths are served under /reflections/ but are not posts.
+var reservedReflectionPaths = map[string]bool{
+	"tags":      true,
+	"archive":   true,
+	"page":      true,
+	"feed.rss":  true,
+	"feed.atom": true,
+	"feed.json": true,
+}
+
+// ReservedSlug reports whether /reflections/<slug> is routed to something
+// other than a post: a fixed page or feed, or a year archive when numeric.
+func ReservedSlug(slug string) bool {
+	return reservedReflectionPaths[slug] || isNumeric(slug)
+}
+
+// ValidateSlug reports why slug can't be used for a post, which would
+// otherwise be unreachable or hide another page.
+func ValidateSlug(slug string) error {
+	switch {
+	case reservedReflectionPaths[slug]:
+		return fmt.Errorf("slug %q is reserved for /reflections/%s", slug, slug)
+	case isNumeric(slug):
+		return fmt.Errorf("slug %q is numeric and would hide the year archive", slug)
+	}
+	return nil
+}
+
+func isNumeric(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Tag struct {
+	Name  string
+	Slug  string
+	Posts []Post
+}
+
+// TagSlug normalises a front matter tag into the form used in tag URLs,
+// e.g. "Web Development" becomes "web-development".
+func TagSlug(tag string) string {
+	var b strings.Builder
+	pendingDash := false
+
+	for _, r := range strings.ToLower(strings.TrimSpace(tag)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingDash = true
+	}
+
+	return b.String()
+}
+
+// GroupByTag collects posts under each normalised tag, preserving post order.
+func GroupByTag(posts []Post) []Tag {
+	var tags []Tag
+	index := make(map[string]int)
+
+	for _, post := range posts {
+		seen := make(map[string]bool)
+		for _, name := range post.Tags {
+			slug := TagSlug(name)
+			if slug == "" || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+
+			i, ok := index[slug]
+			if !ok {
+				i = len(tags)
+				index[slug] = i
+				tags = append(tags, Tag{Name: name, Slug: slug})
+			}
+			tags[i].Posts = append(tags[i].Posts, post)
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// Heading is an entry in a post's table of contents. Children holds the
+// headings nested beneath it.
+type Heading struct {
+	Level    int
+	ID       string
+	Text     string
+	Children []Heading
+}
+
+// tableOfContents collects the headings of doc into a tree.
+func tableOfContents(doc ast.Node, source []byte) []Heading {
+	var flat []Heading
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		id, ok := heading.AttributeString("id")
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+
+		flat = append(flat, Heading{
+			Level: heading.Level,
+			ID:    string(id.([]byte)),
+			Text:  headingText(heading, source),
+		})
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	toc, _ := nestHeadings(flat, 0)
+	return toc
+}
+
+// nestHeadings builds a tree from headings in document order, returning the
+// headings deeper than the heading at parentLevel and how many were consumed.
+func nestHeadings(flat []Heading, parentLevel int) ([]Heading, int) {
+	var tree []Heading
+	i := 0
+	for i < len(flat) && flat[i].Level > parentLevel {
+		heading := flat[i]
+		children, n := nestHeadings(flat[i+1:], heading.Level)
+		heading.Children = children
+		tree = append(tree, heading)
+		i += n + 1
+	}
+	return tree, i
+}
+
+func headingText(heading *ast.Heading, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(heading, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := n.(type) {
+		case *ast.Text:
+			b.Write(t.Value(source))
+			if t.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return html.UnescapeString(strings.TrimSpace(b.String()))
+}
+
+// anchoredHeadings renders headings with a hover anchor link so sections
+// can be linked to directly. It is only used for the post's own page; feeds
+// and search share the plain Content.
+type anchoredHeadings struct{}
+
+func (anchoredHeadings) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHeading, renderAnchoredHeading)
+}
+
+func renderAnchoredHeading(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.Heading)
+	if entering {
+		_, _ = w.WriteString("<h")
+		_ = w.WriteByte("0123456"[n.Level])
+		if n.Attributes() != nil {
+			goldmarkhtml.RenderAttributes(w, node, goldmarkhtml.HeadingAttributeFilter)
+		}
+		_ = w.WriteByte('>')
+		return ast.WalkContinue, nil
+	}
+
+	if id, ok := n.AttributeString("id"); ok {
+		_, _ = fmt.Fprintf(w, `<a href="#%s" class="heading-anchor" title="Link to this section">#</a>`,
+			html.EscapeString(string(id.([]byte))))
+	}
+	_, _ = w.WriteString("</h")
+	_ = w.WriteByte("0123456"[n.Level])
+	_, _ = w.WriteString(">\n")
+	return ast.WalkContinue, nil
+}
@@ -0,0 +1,215 @@
+package ogimage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+
+	"jordanmurray.xyz/site/internal/assets"
+	"jordanmurray.xyz/site/internal/models"
+)
+
+// Width and Height are the dimensions link previews are displayed at.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+const (
+	margin        = 80
+	accentWidth   = 16
+	titleSize     = 64
+	titleMaxLines = 3
+	textSize      = 30
+)
+
+var (
+	background = color.RGBA{0x19, 0x1e, 0x24, 0xff}
+	accent     = color.RGBA{0x8b, 0x5c, 0xf6, 0xff}
+	foreground = color.RGBA{0xf2, 0xf2, 0xf2, 0xff}
+	muted      = color.RGBA{0xa6, 0xad, 0xbb, 0xff}
+)
+
+// Fonts holds the typefaces preview images are drawn with.
+type Fonts struct {
+	Bold    *sfnt.Font
+	Regular *sfnt.Font
+}
+
+// LoadFonts parses the Hack font from the static assets. Builds without the
+// Hack TTFs in static/vendor/fonts fall back to Go Mono.
+func LoadFonts() (Fonts, error) {
+	bold, err := loadFont("vendor/fonts/hack-bold.ttf", gomonobold.TTF)
+	if err != nil {
+		return Fonts{}, err
+	}
+
+	regular, err := loadFont("vendor/fonts/hack-regular.ttf", gomono.TTF)
+	if err != nil {
+		return Fonts{}, err
+	}
+
+	return Fonts{Bold: bold, Regular: regular}, nil
+}
+
+func loadFont(name string, fallback []byte) (*sfnt.Font, error) {
+	data := fallback
+	if asset, _, ok := assets.Lookup(name); ok {
+		data = asset.Data()
+	}
+
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font %s: %w", name, err)
+	}
+
+	return f, nil
+}
+
+// Key identifies the image Render draws for post: it changes exactly when
+// something drawn on the image does, so rendered images can be reused across
+// rebuilds.
+func Key(post models.Post, siteName string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%q\n%q\n%s\n%d\n", siteName, post.Title, post.PublishedAt.Format(time.DateOnly), post.ReadingTime())
+	for _, tag := range post.Tags {
+		fmt.Fprintf(h, "%q\n", tag)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Render draws the preview image for post: the site name, the title, the
+// publication date and reading time, and the post's tags.
+func Render(post models.Post, siteName string, fonts Fonts) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, accentWidth, Height), image.NewUniform(accent), image.Point{}, draw.Src)
+
+	titleFace, err := newFace(fonts.Bold, titleSize)
+	if err != nil {
+		return nil, err
+	}
+	defer titleFace.Close()
+
+	boldFace, err := newFace(fonts.Bold, textSize)
+	if err != nil {
+		return nil, err
+	}
+	defer boldFace.Close()
+
+	regularFace, err := newFace(fonts.Regular, textSize)
+	if err != nil {
+		return nil, err
+	}
+	defer regularFace.Close()
+
+	width := fixed.I(Width - 2*margin)
+
+	y := margin + textSize
+	drawText(img, boldFace, accent, siteName, y)
+
+	y += titleSize * 2
+	for _, line := range wrap(titleFace, post.Title, width, titleMaxLines) {
+		drawText(img, titleFace, foreground, line, y)
+		y += titleSize * 5 / 4
+	}
+
+	y += textSize / 2
+	details := fmt.Sprintf("%s · %d min read", post.PublishedAt.Format("January 2, 2006"), post.ReadingTime())
+	drawText(img, regularFace, muted, details, y)
+
+	if len(post.Tags) > 0 {
+		tags := make([]string, len(post.Tags))
+		for i, tag := range post.Tags {
+			tags[i] = "#" + tag
+		}
+		drawText(img, boldFace, accent, truncate(boldFace, strings.Join(tags, "  "), width), Height-margin)
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.DefaultCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode preview image: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newFace(f *sfnt.Font, size float64) (font.Face, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+	return face, nil
+}
+
+func drawText(img draw.Image, face font.Face, c color.Color, text string, baseline int) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(margin, baseline),
+	}
+	d.DrawString(text)
+}
+
+// wrap breaks text into lines no wider than width, ending with an ellipsis
+// when it needs more than maxLines.
+func wrap(face font.Face, text string, width fixed.Int26_6, maxLines int) []string {
+	var lines []string
+	var line string
+
+	words := strings.Fields(text)
+	for i, word := range words {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line == "" || font.MeasureString(face, candidate) <= width {
+			line = candidate
+			continue
+		}
+
+		if len(lines) == maxLines-1 {
+			return append(lines, truncate(face, line+" "+strings.Join(words[i:], " "), width))
+		}
+		lines = append(lines, truncate(face, line, width))
+		line = word
+	}
+
+	if line != "" {
+		lines = append(lines, truncate(face, line, width))
+	}
+	return lines
+}
+
+// truncate shortens text to fit width, marking the cut with an ellipsis.
+func truncate(face font.Face, text string, width fixed.Int26_6) string {
+	if font.MeasureString(face, text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && font.MeasureString(face, string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
@@ -0,0 +1,48 @@
+package renderer
+
+import (
+	"fmt"
+	"time"
+
+	"jordanmurray.xyz/site/internal/models"
+	"jordanmurray.xyz/site/internal/utils"
+)
+
+type RenderedAtomFeed struct {
+	models.AtomFeed
+	Compressed map[utils.Encoding][]byte
+	etag       string
+}
+
+func NewRenderedAtomFeed(atomFeed models.AtomFeed) (RenderedAtomFeed, error) {
+	compressed, err := utils.CompressAll(atomFeed.Feed)
+	if err != nil {
+		return RenderedAtomFeed{}, fmt.Errorf("error compressing atom feed: %w", err)
+	}
+
+	return RenderedAtomFeed{
+		AtomFeed:   atomFeed,
+		Compressed: compressed,
+		etag:       etag(atomFeed.Feed),
+	}, nil
+}
+
+func (r RenderedAtomFeed) Data() []byte {
+	return r.Feed
+}
+
+func (r RenderedAtomFeed) Encoded(enc utils.Encoding) []byte {
+	return r.Compressed[enc]
+}
+
+func (r RenderedAtomFeed) ETag() string {
+	return r.etag
+}
+
+func (r RenderedAtomFeed) LastModified() time.Time {
+	return r.Updated
+}
+
+func (r RenderedAtomFeed) ContentType() string {
+	return "application/atom+xml; charset=utf-8"
+}
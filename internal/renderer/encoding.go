@@ -0,0 +1,94 @@
+package renderer
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"jordanmurray.xyz/site/internal/utils"
+)
+
+// negotiate selects a content coding for r following RFC 9110 section
+// 12.5.3. Codings are ranked by q-value with ties going to the order of
+// utils.Encodings; identity is acceptable unless excluded and is only chosen
+// over a compressed coding when the client explicitly ranks it higher. ok is
+// false when the client has ruled out every coding, identity included.
+func negotiate(r *http.Request) (enc utils.Encoding, ok bool) {
+	values := r.Header.Values("Accept-Encoding")
+	if len(values) == 0 {
+		return utils.Identity, true
+	}
+
+	weights := make(map[string]float64)
+	wildcard := -1.0
+
+	for _, value := range values {
+		for _, member := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(member, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" {
+				continue
+			}
+			if coding == "x-gzip" {
+				coding = string(utils.Gzip)
+			}
+
+			q := qValue(params)
+			if coding == "*" {
+				wildcard = q
+				continue
+			}
+			weights[coding] = q
+		}
+	}
+
+	weight := func(coding string) (float64, bool) {
+		if q, listed := weights[coding]; listed {
+			return q, true
+		}
+		if wildcard >= 0 {
+			return wildcard, false
+		}
+		return 0, false
+	}
+
+	best, bestQ := utils.Identity, 0.0
+	for _, candidate := range utils.Encodings {
+		if q, _ := weight(string(candidate)); q > bestQ {
+			best, bestQ = candidate, q
+		}
+	}
+
+	identityQ, identityListed := weight(string(utils.Identity))
+	if !identityListed && wildcard < 0 {
+		identityQ = 1
+	}
+
+	switch {
+	case bestQ > 0 && (!identityListed || bestQ >= identityQ):
+		return best, true
+	case identityQ > 0:
+		return utils.Identity, true
+	default:
+		return "", false
+	}
+}
+
+// qValue extracts the weight from a coding's parameters. A missing q means
+// 1; a malformed one is treated as 0 so the coding is never chosen.
+func qValue(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(key), "q") {
+			continue
+		}
+
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || q < 0 || q > 1 {
+			return 0
+		}
+		return q
+	}
+
+	return 1
+}
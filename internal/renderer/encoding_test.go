@@ -0,0 +1,77 @@
+package renderer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"jordanmurray.xyz/site/internal/utils"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding []string
+		want           utils.Encoding
+		wantOK         bool
+	}{
+		{"no header", nil, utils.Identity, true},
+		{"empty header", []string{""}, utils.Identity, true},
+		{"gzip only", []string{"gzip"}, utils.Gzip, true},
+		{"x-gzip alias", []string{"x-gzip"}, utils.Gzip, true},
+		{"case insensitive", []string{"GZIP"}, utils.Gzip, true},
+		{"ties go to server preference", []string{"gzip, zstd, br"}, utils.Brotli, true},
+		{"highest q wins", []string{"br;q=0.5, gzip;q=0.8"}, utils.Gzip, true},
+		{"br excluded", []string{"br;q=0, gzip"}, utils.Gzip, true},
+		{"br excluded alone", []string{"br;q=0"}, utils.Identity, true},
+		{"identity excluded with nothing else", []string{"identity;q=0"}, "", false},
+		{"identity excluded with gzip", []string{"gzip, identity;q=0"}, utils.Gzip, true},
+		{"identity ranked above gzip", []string{"gzip;q=0.5, identity"}, utils.Identity, true},
+		{"identity tied with gzip", []string{"gzip, identity"}, utils.Gzip, true},
+		{"wildcard", []string{"*"}, utils.Brotli, true},
+		{"wildcard excluded", []string{"*;q=0"}, "", false},
+		{"wildcard excluded but identity listed", []string{"*;q=0, identity"}, utils.Identity, true},
+		{"wildcard excluded but gzip listed", []string{"*;q=0, gzip"}, utils.Gzip, true},
+		{"wildcard with br excluded", []string{"*, br;q=0"}, utils.Zstd, true},
+		{"unsupported coding only", []string{"compress"}, utils.Identity, true},
+		{"malformed q never chosen", []string{"br;q=2, gzip;q=0.1"}, utils.Gzip, true},
+		{"q parameter with spaces", []string{"br ; q = 0 , gzip"}, utils.Gzip, true},
+		{"split across headers", []string{"br;q=0", "gzip"}, utils.Gzip, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, value := range tt.acceptEncoding {
+				r.Header.Add("Accept-Encoding", value)
+			}
+
+			got, ok := negotiate(r)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("negotiate(%q) = %q, %v; want %q, %v", tt.acceptEncoding, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWriteNotAcceptable(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "identity;q=0")
+	rec := httptest.NewRecorder()
+
+	Write(rec, r, plain("hello"))
+
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotAcceptable)
+	}
+}
+
+// plain is uncompressed text content.
+type plain string
+
+func (p plain) Data() []byte                      { return []byte(p) }
+func (p plain) Encoded(enc utils.Encoding) []byte { return nil }
+func (p plain) ContentType() string               { return "text/plain; charset=utf-8" }
+func (p plain) ETag() string                      { return etag([]byte(p)) }
+func (p plain) LastModified() time.Time           { return time.Time{} }
@@ -0,0 +1,48 @@
+package renderer
+
+import (
+	"fmt"
+	"time"
+
+	"jordanmurray.xyz/site/internal/models"
+	"jordanmurray.xyz/site/internal/utils"
+)
+
+type RenderedJSONFeed struct {
+	models.JSONFeed
+	Compressed map[utils.Encoding][]byte
+	etag       string
+}
+
+func NewRenderedJSONFeed(jsonFeed models.JSONFeed) (RenderedJSONFeed, error) {
+	compressed, err := utils.CompressAll(jsonFeed.Feed)
+	if err != nil {
+		return RenderedJSONFeed{}, fmt.Errorf("error compressing json feed: %w", err)
+	}
+
+	return RenderedJSONFeed{
+		JSONFeed:   jsonFeed,
+		Compressed: compressed,
+		etag:       etag(jsonFeed.Feed),
+	}, nil
+}
+
+func (r RenderedJSONFeed) Data() []byte {
+	return r.Feed
+}
+
+func (r RenderedJSONFeed) Encoded(enc utils.Encoding) []byte {
+	return r.Compressed[enc]
+}
+
+func (r RenderedJSONFeed) ETag() string {
+	return r.etag
+}
+
+func (r RenderedJSONFeed) LastModified() time.Time {
+	return r.Updated
+}
+
+func (r RenderedJSONFeed) ContentType() string {
+	return "application/feed+json; charset=utf-8"
+}
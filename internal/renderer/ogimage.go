@@ -0,0 +1,51 @@
+package renderer
+
+import (
+	"fmt"
+	"time"
+
+	"jordanmurray.xyz/site/internal/models"
+	"jordanmurray.xyz/site/internal/ogimage"
+	"jordanmurray.xyz/site/internal/utils"
+)
+
+// RenderedOGImage is a post's link preview image. PNG data is already
+// compressed, so it is always served as is.
+type RenderedOGImage struct {
+	PNG     []byte
+	updated time.Time
+	etag    string
+}
+
+func NewRenderedOGImage(post models.Post, siteName string, fonts ogimage.Fonts) (RenderedOGImage, error) {
+	data, err := ogimage.Render(post, siteName, fonts)
+	if err != nil {
+		return RenderedOGImage{}, fmt.Errorf("error rendering preview image: %w", err)
+	}
+
+	return RenderedOGImage{
+		PNG:     data,
+		updated: post.Modified(),
+		etag:    etag(data),
+	}, nil
+}
+
+func (r RenderedOGImage) Data() []byte {
+	return r.PNG
+}
+
+func (r RenderedOGImage) Encoded(enc utils.Encoding) []byte {
+	return nil
+}
+
+func (r RenderedOGImage) ETag() string {
+	return r.etag
+}
+
+func (r RenderedOGImage) LastModified() time.Time {
+	return r.updated
+}
+
+func (r RenderedOGImage) ContentType() string {
+	return "image/png"
+}
@@ -0,0 +1,62 @@
+package renderer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/a-h/templ"
+
+	"jordanmurray.xyz/site/internal/utils"
+)
+
+// RenderedPage is an HTML page rendered once from a component, such as a
+// listing, whose content only changes when the cache is rebuilt.
+type RenderedPage struct {
+	HTML       []byte
+	Compressed map[utils.Encoding][]byte
+	etag       string
+}
+
+func NewRenderedPage(component templ.Component, ctx context.Context) (RenderedPage, error) {
+	var buf bytes.Buffer
+	if err := component.Render(ctx, &buf); err != nil {
+		return RenderedPage{}, fmt.Errorf("error rendering page: %w", err)
+	}
+
+	renderedHTML := buf.Bytes()
+	compressed, err := utils.CompressAll(renderedHTML)
+	if err != nil {
+		return RenderedPage{}, fmt.Errorf("error compressing page: %w", err)
+	}
+
+	return RenderedPage{
+		HTML:       renderedHTML,
+		Compressed: compressed,
+		etag:       etag(renderedHTML),
+	}, nil
+}
+
+func (r RenderedPage) Data() []byte {
+	return r.HTML
+}
+
+func (r RenderedPage) Encoded(enc utils.Encoding) []byte {
+	return r.Compressed[enc]
+}
+
+func (r RenderedPage) ETag() string {
+	return r.etag
+}
+
+// LastModified is unset because listings change with pagination and with
+// posts being published, not only when a listed post is edited; the ETag
+// validates them.
+func (r RenderedPage) LastModified() time.Time {
+	return time.Time{}
+}
+
+func (r RenderedPage) ContentType() string {
+	return "text/html; charset=utf-8"
+}
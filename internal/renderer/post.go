@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"time"
 
 	"jordanmurray.xyz/site/internal/models"
 	"jordanmurray.xyz/site/internal/utils"
@@ -12,27 +13,29 @@ import (
 
 type RenderedPost struct {
 	models.Post
-	HTML           []byte
-	CompressedHTML []byte
+	HTML       []byte
+	Compressed map[utils.Encoding][]byte
+	etag       string
 }
 
-func NewRenderedPost(post models.Post, ctx context.Context) (RenderedPost, error) {
+func NewRenderedPost(post models.Post, nav models.Navigation, ctx context.Context) (RenderedPost, error) {
 	var buf bytes.Buffer
-	component := templates.Reflection(post)
+	component := templates.Reflection(post, nav)
 	if err := component.Render(ctx, &buf); err != nil {
 		return RenderedPost{}, fmt.Errorf("error rendering post: %w", err)
 	}
 
 	renderedHTML := buf.Bytes()
-	compressedHTML, err := utils.Compress(renderedHTML, utils.DefaultCompression)
+	compressed, err := utils.CompressAll(renderedHTML)
 	if err != nil {
 		return RenderedPost{}, fmt.Errorf("error compressing post: %w", err)
 	}
 
 	return RenderedPost{
-		Post:           post,
-		HTML:           renderedHTML,
-		CompressedHTML: compressedHTML,
+		Post:       post,
+		HTML:       renderedHTML,
+		Compressed: compressed,
+		etag:       etag(renderedHTML),
 	}, nil
 }
 
@@ -40,8 +43,18 @@ func (r RenderedPost) Data() []byte {
 	return r.HTML
 }
 
-func (r RenderedPost) CompressedData() []byte {
-	return r.CompressedHTML
+func (r RenderedPost) Encoded(enc utils.Encoding) []byte {
+	return r.Compressed[enc]
+}
+
+func (r RenderedPost) ETag() string {
+	return r.etag
+}
+
+// LastModified is unset because the page also shows the titles of linked
+// posts, which change without this post changing; the ETag validates it.
+func (r RenderedPost) LastModified() time.Time {
+	return time.Time{}
 }
 
 func (r RenderedPost) ContentType() string {
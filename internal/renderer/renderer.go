@@ -1,24 +1,53 @@
 package renderer
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
+
+	"jordanmurray.xyz/site/internal/utils"
 )
 
+// Renderer is content prepared ahead of time. Encoded returns nil for any
+// encoding that was not precomputed, in which case Data is served instead.
 type Renderer interface {
 	Data() []byte
-	CompressedData() []byte
+	Encoded(enc utils.Encoding) []byte
 	ContentType() string
+	ETag() string
+	LastModified() time.Time
+}
+
+// etag derives a strong validator from uncompressed content. It is computed
+// once when a Renderer is built since rendered content never changes.
+func etag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf(`"%x"`, sum[:16])
 }
 
 func Write(w http.ResponseWriter, r *http.Request, resp Renderer) {
+	w.Header().Set("Vary", "Accept-Encoding")
+
+	enc, ok := negotiate(r)
+	if !ok {
+		http.Error(w, "no acceptable content coding", http.StatusNotAcceptable)
+		return
+	}
+
 	w.Header().Set("Content-Type", resp.ContentType())
 
-	if strings.Contains(r.Header.Get("Accept-Encoding"), "br") {
-		w.Header().Set("Content-Encoding", "br")
-		w.Header().Set("Vary", "Accept-Encoding")
-		w.Write(resp.CompressedData())
-	} else {
-		w.Write(resp.Data())
+	data, tag := resp.Data(), resp.ETag()
+	if encoded := resp.Encoded(enc); enc != utils.Identity && encoded != nil {
+		w.Header().Set("Content-Encoding", string(enc))
+		data = encoded
+		tag = strings.TrimSuffix(tag, `"`) + "-" + string(enc) + `"`
 	}
+	w.Header().Set("ETag", tag)
+
+	// ServeContent answers If-None-Match and If-Modified-Since with a 304 and
+	// omits the body for HEAD requests.
+	http.ServeContent(w, r, "", resp.LastModified(), bytes.NewReader(data))
 }
@@ -2,24 +2,28 @@ package renderer
 
 import (
 	"fmt"
+	"time"
+
 	"jordanmurray.xyz/site/internal/models"
 	"jordanmurray.xyz/site/internal/utils"
 )
 
 type RenderedRSSFeed struct {
 	models.RSSFeed
-	CompressedFeed []byte
+	Compressed map[utils.Encoding][]byte
+	etag       string
 }
 
 func NewRenderedRSSFeed(rssFeed models.RSSFeed) (RenderedRSSFeed, error) {
-	compressedFeed, err := utils.Compress(rssFeed.Feed, utils.DefaultCompression)
+	compressed, err := utils.CompressAll(rssFeed.Feed)
 	if err != nil {
 		return RenderedRSSFeed{}, fmt.Errorf("error compressing rss feed: %w", err)
 	}
 
 	return RenderedRSSFeed{
-		RSSFeed:        rssFeed,
-		CompressedFeed: compressedFeed,
+		RSSFeed:    rssFeed,
+		Compressed: compressed,
+		etag:       etag(rssFeed.Feed),
 	}, nil
 }
 
@@ -27,8 +31,16 @@ func (r RenderedRSSFeed) Data() []byte {
 	return r.Feed
 }
 
-func (r RenderedRSSFeed) CompressedData() []byte {
-	return r.CompressedFeed
+func (r RenderedRSSFeed) Encoded(enc utils.Encoding) []byte {
+	return r.Compressed[enc]
+}
+
+func (r RenderedRSSFeed) ETag() string {
+	return r.etag
+}
+
+func (r RenderedRSSFeed) LastModified() time.Time {
+	return r.Updated
 }
 
 func (r RenderedRSSFeed) ContentType() string {
@@ -0,0 +1,89 @@
+package renderer
+
+import (
+	"fmt"
+	"time"
+
+	"jordanmurray.xyz/site/internal/models"
+	"jordanmurray.xyz/site/internal/utils"
+)
+
+type RenderedSitemap struct {
+	models.Sitemap
+	Compressed map[utils.Encoding][]byte
+	etag       string
+}
+
+func NewRenderedSitemap(sitemap models.Sitemap) (RenderedSitemap, error) {
+	compressed, err := utils.CompressAll(sitemap.XML)
+	if err != nil {
+		return RenderedSitemap{}, fmt.Errorf("error compressing sitemap: %w", err)
+	}
+
+	return RenderedSitemap{
+		Sitemap:    sitemap,
+		Compressed: compressed,
+		etag:       etag(sitemap.XML),
+	}, nil
+}
+
+func (r RenderedSitemap) Data() []byte {
+	return r.XML
+}
+
+func (r RenderedSitemap) Encoded(enc utils.Encoding) []byte {
+	return r.Compressed[enc]
+}
+
+func (r RenderedSitemap) ETag() string {
+	return r.etag
+}
+
+func (r RenderedSitemap) LastModified() time.Time {
+	return r.Updated
+}
+
+func (r RenderedSitemap) ContentType() string {
+	return "application/xml; charset=utf-8"
+}
+
+// RenderedRobots serves robots.txt. It has no meaningful modification time,
+// so caches revalidate it by ETag alone.
+type RenderedRobots struct {
+	models.Robots
+	Compressed map[utils.Encoding][]byte
+	etag       string
+}
+
+func NewRenderedRobots(robots models.Robots) (RenderedRobots, error) {
+	compressed, err := utils.CompressAll(robots.Text)
+	if err != nil {
+		return RenderedRobots{}, fmt.Errorf("error compressing robots.txt: %w", err)
+	}
+
+	return RenderedRobots{
+		Robots:     robots,
+		Compressed: compressed,
+		etag:       etag(robots.Text),
+	}, nil
+}
+
+func (r RenderedRobots) Data() []byte {
+	return r.Text
+}
+
+func (r RenderedRobots) Encoded(enc utils.Encoding) []byte {
+	return r.Compressed[enc]
+}
+
+func (r RenderedRobots) ETag() string {
+	return r.etag
+}
+
+func (r RenderedRobots) LastModified() time.Time {
+	return time.Time{}
+}
+
+func (r RenderedRobots) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
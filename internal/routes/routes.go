@@ -0,0 +1,83 @@
+// Package routes maps the site's URLs onto their handlers.
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"jordanmurray.xyz/site/internal/handlers"
+	"jordanmurray.xyz/site/internal/metrics"
+	"jordanmurray.xyz/site/internal/models"
+)
+
+// New registers every route the site serves. The admin reload endpoint is
+// only registered when adminToken is non-empty.
+func New(adminToken string) *http.ServeMux {
+	mux := router{http.NewServeMux()}
+	mux.HandleFunc("GET /{$}", handlers.HandleHome)
+	// /health predates the split into liveness and readiness probes.
+	mux.HandleFunc("GET /health", handlers.HandleReadyz)
+	mux.HandleFunc("GET /healthz", handlers.HandleHealthz)
+	mux.HandleFunc("GET /readyz", handlers.HandleReadyz)
+	mux.HandleFunc("GET /version", handlers.HandleVersion)
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("GET /reflections", handlers.HandleReflections)
+	mux.HandleFunc("GET /reflections/page/{n}", handlers.HandleReflectionsPage)
+	mux.HandleFunc("GET /reflections/archive", handlers.HandleArchive)
+	mux.HandleFunc("GET /reflections/{parent}/{child}", handlers.HandleReflectionSubpath)
+	mux.HandleFunc("GET /reflections/{slug}", handlers.HandleReflection)
+	mux.HandleFunc("GET /reflections/tags", handlers.HandleTags)
+	mux.HandleFunc("GET /reflections/tags/{tag}", handlers.HandleTag)
+	mux.HandleFunc("GET /reflections/tags/{tag}/feed.rss", handlers.HandleTagRSS)
+	mux.HandleFunc("HEAD /reflections/tags/{tag}/feed.rss", handlers.HandleTagRSS)
+	mux.HandleFunc("GET /reflections/feed.rss", handlers.HandleRSS)
+	mux.HandleFunc("HEAD /reflections/feed.rss", handlers.HandleRSS)
+	mux.HandleFunc("GET /reflections/feed.atom", handlers.HandleAtom)
+	mux.HandleFunc("HEAD /reflections/feed.atom", handlers.HandleAtom)
+	mux.HandleFunc("GET /reflections/feed.json", handlers.HandleJSONFeed)
+	mux.HandleFunc("HEAD /reflections/feed.json", handlers.HandleJSONFeed)
+	mux.HandleFunc("GET /sitemap.xml", handlers.HandleSitemap)
+	mux.HandleFunc("HEAD /sitemap.xml", handlers.HandleSitemap)
+	mux.HandleFunc("GET /robots.txt", handlers.HandleRobots)
+	mux.HandleFunc("HEAD /robots.txt", handlers.HandleRobots)
+	mux.HandleFunc("GET /search", handlers.HandleSearch)
+	mux.HandleFunc("GET /search/results", handlers.HandleSearchResults)
+	if adminToken != "" {
+		mux.HandleFunc("POST /admin/reload", handlers.HandleReload(adminToken))
+	}
+	mux.HandleFunc("GET /static/{path...}", handlers.HandleStatic)
+
+	return mux.ServeMux
+}
+
+// router refuses routes whose first segment under /reflections/ is a literal
+// that posts may still use as a slug, since the route would make such a post
+// or its preview image unreachable.
+type router struct {
+	*http.ServeMux
+}
+
+func (r router) Handle(pattern string, handler http.Handler) {
+	checkReserved(pattern)
+	r.ServeMux.Handle(pattern, handler)
+}
+
+func (r router) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	checkReserved(pattern)
+	r.ServeMux.HandleFunc(pattern, handler)
+}
+
+func checkReserved(pattern string) {
+	_, path, _ := strings.Cut(pattern, " ")
+	rest, ok := strings.CutPrefix(path, "/reflections/")
+	if !ok {
+		return
+	}
+
+	segment, _, _ := strings.Cut(rest, "/")
+	if segment == "" || strings.HasPrefix(segment, "{") || models.ReservedSlug(segment) {
+		return
+	}
+	panic(fmt.Sprintf("route %q shadows posts with the slug %q; reserve it in models", pattern, segment))
+}
@@ -0,0 +1,44 @@
+package routes
+
+import "testing"
+
+// TestNewReservesReflectionPaths fails when a route is added under
+// /reflections/ without reserving its first segment from post slugs.
+func TestNewReservesReflectionPaths(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatal(r)
+		}
+	}()
+
+	New("token")
+}
+
+func TestCheckReserved(t *testing.T) {
+	tests := []struct {
+		pattern string
+		allowed bool
+	}{
+		{"GET /reflections/{slug}", true},
+		{"GET /reflections/{parent}/{child}", true},
+		{"GET /reflections/page/{n}", true},
+		{"GET /reflections/tags/{tag}/feed.rss", true},
+		{"GET /reflections/feed.rss", true},
+		{"GET /reflections", true},
+		{"GET /search", true},
+		{"GET /reflections/drafts/{slug}", false},
+		{"GET /reflections/random", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r == nil) != tt.allowed {
+					t.Errorf("checkReserved(%q) panicked = %v, want %v", tt.pattern, r != nil, !tt.allowed)
+				}
+			}()
+
+			checkReserved(tt.pattern)
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package search
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"jordanmurray.xyz/site/internal/models"
+)
+
+// BM25 tuning constants, using the commonly recommended defaults.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// fieldGap separates fields in a document's position space so that phrase
+// queries never match across the end of the title and the start of the body.
+const fieldGap = 1000
+
+// Field weights boost terms found in more descriptive parts of a post.
+const (
+	titleWeight   = 3.0
+	tagWeight     = 2.0
+	excerptWeight = 1.5
+	bodyWeight    = 1.0
+)
+
+// snippetWords is the number of words of context shown around a match.
+const snippetWords = 30
+
+var (
+	tagPattern   = regexp.MustCompile(`<[^>]*>`)
+	queryPattern = regexp.MustCompile(`"([^"]*)"?|\S+`)
+)
+
+// Index is an immutable inverted index over posts. It is built once per
+// cache snapshot and is safe for concurrent use.
+type Index struct {
+	docs     []document
+	postings map[string][]posting
+	avgLen   float64
+}
+
+type document struct {
+	post   models.Post
+	text   string
+	length float64
+}
+
+// posting records where a term appears in one document.
+type posting struct {
+	doc       int
+	tf        float64
+	positions []int
+}
+
+// Result is a post matching a query, best matches first.
+type Result struct {
+	Post    models.Post
+	Score   float64
+	Snippet []Segment
+}
+
+// Segment is a run of snippet text. Match marks text that matched the query
+// and should be highlighted.
+type Segment struct {
+	Text  string
+	Match bool
+}
+
+type token struct {
+	term       string
+	start, end int
+}
+
+// NewIndex indexes the title, tags, excerpt and plain text content of posts.
+func NewIndex(posts []models.Post) *Index {
+	idx := &Index{
+		docs:     make([]document, len(posts)),
+		postings: make(map[string][]posting),
+	}
+
+	var total float64
+	for i, post := range posts {
+		text := PlainText(post.Content)
+		fields := []struct {
+			text   string
+			weight float64
+		}{
+			{post.Title, titleWeight},
+			{strings.Join(post.Tags, " "), tagWeight},
+			{post.Excerpt, excerptWeight},
+			{text, bodyWeight},
+		}
+
+		terms := make(map[string]*posting)
+		var length float64
+		for f, field := range fields {
+			for pos, tok := range tokenize(field.text) {
+				p, ok := terms[tok.term]
+				if !ok {
+					p = &posting{doc: i}
+					terms[tok.term] = p
+				}
+				p.tf += field.weight
+				p.positions = append(p.positions, f*fieldGap+pos)
+				length += field.weight
+			}
+		}
+
+		for term, p := range terms {
+			idx.postings[term] = append(idx.postings[term], *p)
+		}
+		idx.docs[i] = document{post: post, text: text, length: length}
+		total += length
+	}
+
+	if len(posts) > 0 {
+		idx.avgLen = total / float64(len(posts))
+	}
+
+	return idx
+}
+
+// relativeLength is a document's length compared to the average, taken as 1
+// when every document is empty so scores never become NaN.
+func (idx *Index) relativeLength(doc int) float64 {
+	if idx.avgLen == 0 {
+		return 1
+	}
+	return idx.docs[doc].length / idx.avgLen
+}
+
+// Search ranks posts against query using BM25. Quoted phrases must appear
+// verbatim; other terms contribute to the ranking of any post containing
+// at least one of them. At most limit results are returned.
+func (idx *Index) Search(query string, limit int) []Result {
+	terms, phrases := parseQuery(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	for _, term := range terms {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		n, df := float64(len(idx.docs)), float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			norm := k1 * (1 - b + b*idx.relativeLength(p.doc))
+			scores[p.doc] += idf * p.tf * (k1 + 1) / (p.tf + norm)
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for doc, score := range scores {
+		if !idx.containsPhrases(doc, phrases) {
+			continue
+		}
+		results = append(results, Result{
+			Post:    idx.docs[doc].post,
+			Score:   score,
+			Snippet: idx.snippet(doc, terms),
+		})
+	}
+
+	slices.SortFunc(results, func(a, b Result) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return b.Post.PublishedAt.Compare(a.Post.PublishedAt)
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+func (idx *Index) containsPhrases(doc int, phrases [][]string) bool {
+	for _, phrase := range phrases {
+		if !idx.containsPhrase(doc, phrase) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsPhrase reports whether the terms of phrase appear at consecutive
+// positions within a single field of doc.
+func (idx *Index) containsPhrase(doc int, phrase []string) bool {
+	positions := make([]map[int]bool, len(phrase))
+	for i, term := range phrase {
+		positions[i] = make(map[int]bool)
+		for _, p := range idx.postings[term] {
+			if p.doc == doc {
+				for _, pos := range p.positions {
+					positions[i][pos] = true
+				}
+				break
+			}
+		}
+	}
+
+	for start := range positions[0] {
+		matched := true
+		for i := 1; i < len(phrase); i++ {
+			if !positions[i][start+i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// snippet picks the window of the post's text with the most matching words,
+// falling back to the excerpt when only the title or tags matched.
+func (idx *Index) snippet(doc int, terms []string) []Segment {
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	segments := highlight(idx.docs[doc].text, wanted)
+	if segments == nil {
+		segments = highlight(idx.docs[doc].post.Excerpt, wanted)
+	}
+	if segments == nil {
+		return []Segment{{Text: idx.docs[doc].post.Excerpt}}
+	}
+
+	return segments
+}
+
+func highlight(text string, wanted map[string]bool) []Segment {
+	tokens := tokenize(text)
+
+	best, bestCount, count := 0, 0, 0
+	for i, tok := range tokens {
+		if wanted[tok.term] {
+			count++
+		}
+		if i >= snippetWords && wanted[tokens[i-snippetWords].term] {
+			count--
+		}
+		if count > bestCount {
+			bestCount = count
+			best = max(0, i-snippetWords+1)
+		}
+	}
+	if bestCount == 0 {
+		return nil
+	}
+
+	// Lead with a little context before the first match rather than a run of
+	// unrelated words.
+	for i := best; i < len(tokens); i++ {
+		if wanted[tokens[i].term] {
+			best = max(0, i-snippetWords/4)
+			break
+		}
+	}
+
+	last := min(best+snippetWords, len(tokens))
+	start, end := 0, len(text)
+
+	var segments []Segment
+	if best > 0 {
+		start = tokens[best].start
+		segments = append(segments, Segment{Text: "… "})
+	}
+	if last < len(tokens) {
+		end = tokens[last-1].end
+	}
+
+	offset := start
+	for _, tok := range tokens[best:last] {
+		if !wanted[tok.term] {
+			continue
+		}
+		if tok.start > offset {
+			segments = append(segments, Segment{Text: text[offset:tok.start]})
+		}
+		segments = append(segments, Segment{Text: text[tok.start:tok.end], Match: true})
+		offset = tok.end
+	}
+	if end > offset {
+		segments = append(segments, Segment{Text: text[offset:end]})
+	}
+	if last < len(tokens) {
+		segments = append(segments, Segment{Text: " …"})
+	}
+
+	return segments
+}
+
+// parseQuery splits a query into the stemmed terms used for ranking and the
+// quoted phrases a result must contain.
+func parseQuery(query string) ([]string, [][]string) {
+	var terms []string
+	var phrases [][]string
+	seen := make(map[string]bool)
+
+	for _, match := range queryPattern.FindAllStringSubmatch(query, -1) {
+		text := match[0]
+		if strings.HasPrefix(text, `"`) {
+			text = match[1]
+		}
+
+		var phrase []string
+		for _, tok := range tokenize(text) {
+			phrase = append(phrase, tok.term)
+			if !seen[tok.term] {
+				seen[tok.term] = true
+				terms = append(terms, tok.term)
+			}
+		}
+
+		if strings.HasPrefix(match[0], `"`) && len(phrase) > 1 {
+			phrases = append(phrases, phrase)
+		}
+	}
+
+	return terms, phrases
+}
+
+// tokenize splits text into lowercase, stemmed words along with their byte
+// offsets in text.
+func tokenize(text string) []token {
+	var tokens []token
+
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, newToken(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, newToken(text, start, len(text)))
+	}
+
+	return tokens
+}
+
+func newToken(text string, start, end int) token {
+	return token{term: stem(strings.ToLower(text[start:end])), start: start, end: end}
+}
+
+// PlainText strips markup from rendered HTML, leaving readable text with
+// entities decoded and whitespace collapsed.
+func PlainText(content string) string {
+	text := html.UnescapeString(tagPattern.ReplaceAllString(content, " "))
+	if !utf8.ValidString(text) {
+		text = strings.ToValidUTF8(text, "")
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,141 @@
+package search
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"jordanmurray.xyz/site/internal/models"
+)
+
+func post(slug, title, content string, published time.Time, tags ...string) models.Post {
+	return models.Post{
+		Slug:    slug,
+		Content: content,
+		FrontMatter: models.FrontMatter{
+			Title:       title,
+			PublishedAt: published,
+			Tags:        tags,
+		},
+	}
+}
+
+var day = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func slugs(results []Result) []string {
+	var s []string
+	for _, r := range results {
+		s = append(s, r.Post.Slug)
+	}
+	return s
+}
+
+func TestSearch(t *testing.T) {
+	idx := NewIndex([]models.Post{
+		post("title", "Publishing with Go", "<p>Notes on shipping a site.</p>", day),
+		post("body", "Weekend notes", "<p>I spent the weekend publishing a site written in Go and Rust.</p>", day.AddDate(0, 0, 1)),
+		post("tagged", "Misc", "<p>Nothing much.</p>", day.AddDate(0, 0, 2), "rust"),
+		post("phrase", "Static sites", "<p>A static site generator renders pages ahead of time.</p>", day.AddDate(0, 0, 3)),
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"title outranks body", "publishing", []string{"title", "body"}},
+		{"stemmed forms match", "published", []string{"title", "body"}},
+		{"tags outrank body", "rust", []string{"tagged", "body"}},
+		{"phrase must be consecutive", `"static site"`, []string{"phrase"}},
+		{"phrase out of order", `"site static"`, nil},
+		{"phrase does not span fields", `"sites static"`, nil},
+		{"unknown term", "kubernetes", nil},
+		{"empty query", "   ", nil},
+		{"punctuation only", "?!", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := idx.Search(tt.query, 10)
+			got := slugs(results)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+			for _, r := range results {
+				if math.IsNaN(r.Score) || math.IsInf(r.Score, 0) || r.Score <= 0 {
+					t.Errorf("Search(%q) scored %s %v", tt.query, r.Post.Slug, r.Score)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchLengthNormalisation(t *testing.T) {
+	idx := NewIndex([]models.Post{
+		post("long", "A", "<p>go and a great many other words that dilute the one mention of the term</p>", day),
+		post("short", "B", "<p>go home</p>", day),
+	})
+
+	if got := slugs(idx.Search("go", 10)); len(got) != 2 || got[0] != "short" {
+		t.Errorf("Search(go) = %v, want the shorter post first", got)
+	}
+}
+
+func TestSearchTiesNewestFirst(t *testing.T) {
+	idx := NewIndex([]models.Post{
+		post("older", "Go", "", day),
+		post("newer", "Go", "", day.AddDate(0, 1, 0)),
+	})
+
+	if got := slugs(idx.Search("go", 10)); len(got) != 2 || got[0] != "newer" {
+		t.Errorf("Search(go) = %v, want the newer post first", got)
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	var posts []models.Post
+	for i := range 5 {
+		posts = append(posts, post(string(rune('a'+i)), "Go", "", day.AddDate(0, 0, i)))
+	}
+
+	if got := NewIndex(posts).Search("go", 3); len(got) != 3 {
+		t.Errorf("Search(go, 3) returned %d results", len(got))
+	}
+}
+
+func TestRelativeLengthOfEmptyIndex(t *testing.T) {
+	idx := NewIndex([]models.Post{post("empty", "", "", day)})
+
+	if got := idx.relativeLength(0); got != 1 {
+		t.Errorf("relativeLength = %v, want 1", got)
+	}
+	if got := idx.Search("anything", 10); len(got) != 0 {
+		t.Errorf("Search on empty posts = %v, want none", slugs(got))
+	}
+}
+
+func TestSnippetHighlightsMatches(t *testing.T) {
+	idx := NewIndex([]models.Post{
+		post("p", "Title", "<p>Before the <em>publishing</em> step, and after it.</p>", day),
+	})
+
+	results := idx.Search("publish", 10)
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+
+	var matches []string
+	for _, seg := range results[0].Snippet {
+		if seg.Match {
+			matches = append(matches, seg.Text)
+		}
+	}
+	if len(matches) != 1 || matches[0] != "publishing" {
+		t.Errorf("highlighted %q, want [publishing]", matches)
+	}
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"math"
+	"slices"
+
+	"jordanmurray.xyz/site/internal/models"
+)
+
+// minRelatedScore keeps posts that merely share a few common words from
+// being suggested as related.
+const minRelatedScore = 0.1
+
+// Related returns up to n related posts for every indexed post, keyed by
+// slug. Posts are scored by the overlap of their tags plus the cosine
+// similarity of their TF-IDF weighted terms, each contributing up to 1.
+func (idx *Index) Related(n int) map[string][]models.Post {
+	vectors := make([]map[string]float64, len(idx.docs))
+	norms := make([]float64, len(idx.docs))
+	for i := range vectors {
+		vectors[i] = make(map[string]float64)
+	}
+
+	for term, postings := range idx.postings {
+		idf := math.Log(float64(len(idx.docs)) / float64(len(postings)))
+		if idf == 0 {
+			continue
+		}
+		for _, p := range postings {
+			weight := p.tf * idf
+			vectors[p.doc][term] = weight
+			norms[p.doc] += weight * weight
+		}
+	}
+	for i := range norms {
+		norms[i] = math.Sqrt(norms[i])
+	}
+
+	type candidate struct {
+		post  models.Post
+		score float64
+	}
+
+	related := make(map[string][]models.Post, len(idx.docs))
+	for i, doc := range idx.docs {
+		var candidates []candidate
+		for j, other := range idx.docs {
+			if i == j {
+				continue
+			}
+
+			score := tagOverlap(doc.post.Tags, other.post.Tags)
+			if norms[i] > 0 && norms[j] > 0 {
+				score += dot(vectors[i], vectors[j]) / (norms[i] * norms[j])
+			}
+			if score >= minRelatedScore {
+				candidates = append(candidates, candidate{post: other.post, score: score})
+			}
+		}
+
+		slices.SortFunc(candidates, func(a, b candidate) int {
+			if a.score != b.score {
+				if a.score > b.score {
+					return -1
+				}
+				return 1
+			}
+			return b.post.PublishedAt.Compare(a.post.PublishedAt)
+		})
+
+		posts := make([]models.Post, 0, min(n, len(candidates)))
+		for _, c := range candidates[:min(n, len(candidates))] {
+			posts = append(posts, c.post)
+		}
+		related[doc.post.Slug] = posts
+	}
+
+	return related
+}
+
+// tagOverlap is the Jaccard similarity of two posts' tags.
+func tagOverlap(a, b []string) float64 {
+	set := make(map[string]bool, len(a))
+	for _, tag := range a {
+		set[models.TagSlug(tag)] = true
+	}
+
+	union := len(set)
+	shared := 0
+	seen := make(map[string]bool, len(b))
+	for _, tag := range b {
+		slug := models.TagSlug(tag)
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		if set[slug] {
+			shared++
+		} else {
+			union++
+		}
+	}
+
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+func dot(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+
+	var sum float64
+	for term, weight := range a {
+		sum += weight * b[term]
+	}
+	return sum
+}
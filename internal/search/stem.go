@@ -0,0 +1,237 @@
+package search
+
+import "strings"
+
+// stem reduces an English word to its root using the Porter stemming
+// algorithm, so that "publishing" and "published" both match "publish".
+// Words that are not plain lowercase ASCII are returned unchanged.
+func stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	s := &stemmer{b: []byte(word)}
+	s.step1a()
+	s.step1b()
+	s.step1c()
+	s.step2()
+	s.step3()
+	s.step4()
+	s.step5()
+	return string(s.b)
+}
+
+type stemmer struct {
+	b []byte
+}
+
+func (s *stemmer) consonant(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.consonant(i-1)
+	}
+	return true
+}
+
+// measure counts the vowel-consonant sequences in b[:end].
+func (s *stemmer) measure(end int) int {
+	m, i := 0, 0
+	for i < end && s.consonant(i) {
+		i++
+	}
+	for i < end {
+		for i < end && !s.consonant(i) {
+			i++
+		}
+		if i >= end {
+			break
+		}
+		for i < end && s.consonant(i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+func (s *stemmer) hasVowel(end int) bool {
+	for i := 0; i < end; i++ {
+		if !s.consonant(i) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *stemmer) doubleConsonant(end int) bool {
+	return end >= 2 && s.b[end-1] == s.b[end-2] && s.consonant(end-1)
+}
+
+// cvc reports whether b[:end] ends consonant-vowel-consonant where the final
+// consonant is not w, x or y.
+func (s *stemmer) cvc(end int) bool {
+	if end < 3 || !s.consonant(end-1) || s.consonant(end-2) || !s.consonant(end-3) {
+		return false
+	}
+	switch s.b[end-1] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+func (s *stemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.b), suffix)
+}
+
+func (s *stemmer) replace(suffix, replacement string) {
+	s.b = append(s.b[:len(s.b)-len(suffix)], replacement...)
+}
+
+// replaceIf applies the first matching rule whose stem has a measure above
+// minMeasure. Only the longest matching suffix is considered.
+func (s *stemmer) replaceIf(rules [][2]string, minMeasure int) {
+	for _, rule := range rules {
+		if s.hasSuffix(rule[0]) {
+			if s.measure(len(s.b)-len(rule[0])) > minMeasure {
+				s.replace(rule[0], rule[1])
+			}
+			return
+		}
+	}
+}
+
+func (s *stemmer) step1a() {
+	switch {
+	case s.hasSuffix("sses"):
+		s.replace("sses", "ss")
+	case s.hasSuffix("ies"):
+		s.replace("ies", "i")
+	case s.hasSuffix("ss"):
+	case s.hasSuffix("s"):
+		s.replace("s", "")
+	}
+}
+
+func (s *stemmer) step1b() {
+	if s.hasSuffix("eed") {
+		if s.measure(len(s.b)-3) > 0 {
+			s.replace("eed", "ee")
+		}
+		return
+	}
+
+	removed := false
+	for _, suffix := range []string{"ed", "ing"} {
+		if s.hasSuffix(suffix) && s.hasVowel(len(s.b)-len(suffix)) {
+			s.replace(suffix, "")
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		return
+	}
+
+	end := len(s.b)
+	switch {
+	case s.hasSuffix("at"), s.hasSuffix("bl"), s.hasSuffix("iz"):
+		s.b = append(s.b, 'e')
+	case s.doubleConsonant(end):
+		switch s.b[end-1] {
+		case 'l', 's', 'z':
+		default:
+			s.b = s.b[:end-1]
+		}
+	case s.measure(end) == 1 && s.cvc(end):
+		s.b = append(s.b, 'e')
+	}
+}
+
+func (s *stemmer) step1c() {
+	if s.hasSuffix("y") && s.hasVowel(len(s.b)-1) {
+		s.b[len(s.b)-1] = 'i'
+	}
+}
+
+var step2Rules = [][2]string{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"},
+	{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+	{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+	{"logi", "log"},
+}
+
+var step3Rules = [][2]string{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+func (s *stemmer) step2() {
+	s.replaceIf(longestFirst(step2Rules), 0)
+}
+
+func (s *stemmer) step3() {
+	s.replaceIf(longestFirst(step3Rules), 0)
+}
+
+func (s *stemmer) step4() {
+	var match string
+	for _, suffix := range step4Suffixes {
+		if s.hasSuffix(suffix) && len(suffix) > len(match) {
+			match = suffix
+		}
+	}
+	if match == "" {
+		return
+	}
+
+	end := len(s.b) - len(match)
+	if s.measure(end) <= 1 {
+		return
+	}
+	if match == "ion" && (end == 0 || (s.b[end-1] != 's' && s.b[end-1] != 't')) {
+		return
+	}
+	s.b = s.b[:end]
+}
+
+func (s *stemmer) step5() {
+	if s.hasSuffix("e") {
+		end := len(s.b) - 1
+		if m := s.measure(end); m > 1 || (m == 1 && !s.cvc(end)) {
+			s.b = s.b[:end]
+		}
+	}
+
+	end := len(s.b)
+	if s.measure(end) > 1 && s.doubleConsonant(end) && s.b[end-1] == 'l' {
+		s.b = s.b[:end-1]
+	}
+}
+
+// longestFirst orders rules so that the longest matching suffix wins, as
+// the algorithm requires.
+func longestFirst(rules [][2]string) [][2]string {
+	sorted := make([][2]string, len(rules))
+	copy(sorted, rules)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && len(sorted[j][0]) > len(sorted[j-1][0]); j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+	return sorted
+}
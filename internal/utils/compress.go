@@ -2,8 +2,11 @@ package utils
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
+
 	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 )
 
 const (
@@ -12,6 +15,20 @@ const (
 	DefaultCompression = brotli.DefaultCompression
 )
 
+// Encoding is an HTTP content coding.
+type Encoding string
+
+const (
+	Brotli   Encoding = "br"
+	Zstd     Encoding = "zstd"
+	Gzip     Encoding = "gzip"
+	Identity Encoding = "identity"
+)
+
+// Encodings lists the compressed codings precomputed for every response, in
+// the order the server prefers them when a client has no preference.
+var Encodings = []Encoding{Brotli, Zstd, Gzip}
+
 func Compress(content []byte, compressionLevel int) ([]byte, error) {
 	var compressed bytes.Buffer
 	writer := brotli.NewWriterLevel(&compressed, compressionLevel)
@@ -26,3 +43,55 @@ func Compress(content []byte, compressionLevel int) ([]byte, error) {
 
 	return compressed.Bytes(), nil
 }
+
+func CompressGzip(content []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&compressed, gzip.BestCompression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip writer: %w", err)
+	}
+
+	if _, err := writer.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to gzip: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close gzip writer: %w", err)
+	}
+
+	return compressed.Bytes(), nil
+}
+
+func CompressZstd(content []byte) ([]byte, error) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+	defer encoder.Close()
+
+	return encoder.EncodeAll(content, nil), nil
+}
+
+// CompressAll precomputes content in each of Encodings.
+func CompressAll(content []byte) (map[Encoding][]byte, error) {
+	br, err := Compress(content, DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	zst, err := CompressZstd(content)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := CompressGzip(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[Encoding][]byte{
+		Brotli: br,
+		Zstd:   zst,
+		Gzip:   gz,
+	}, nil
+}
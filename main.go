@@ -5,13 +5,18 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
-	"net/http"
+	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"jordanmurray.xyz/site/internal/assets"
 	"jordanmurray.xyz/site/internal/cache"
-	"jordanmurray.xyz/site/internal/handlers"
 	"jordanmurray.xyz/site/internal/models"
+	"jordanmurray.xyz/site/internal/routes"
 )
 
 var (
@@ -23,6 +28,62 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		case "build":
+			os.Exit(runBuild(os.Args[2:]))
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q\nusage: site [serve|check|build]\n", os.Args[1])
+			os.Exit(2)
+		}
+	}
+
+	serve()
+}
+
+func serve() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	serverConfig, err := loadServerConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error configuring server: %v\n", err)
+		os.Exit(1)
+	}
+
+	contentDir, err := hydrate(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error hydrating cache: %v\n", err)
+		os.Exit(1)
+	}
+
+	if contentDir != "" && serverConfig.WatchInterval > 0 {
+		go cache.Watch(ctx, contentDir, serverConfig.WatchInterval)
+	}
+
+	go reloadOnHangup(ctx)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "9090"
+	}
+
+	mux := routes.New(os.Getenv("ADMIN_TOKEN"))
+
+	addr := fmt.Sprintf(":%s", port)
+	if err := listenAndServe(ctx, stop, addr, withMiddleware(mux, serverConfig), serverConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "error serving: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// hydrate loads content into the cache from CONTENT_DIR when set, falling
+// back to the content embedded in the binary. It returns the directory used,
+// which is empty for embedded content.
+func hydrate(ctx context.Context) (string, error) {
 	rssBaseURL := os.Getenv("RSS_BASE_URL")
 	if rssBaseURL == "" {
 		rssBaseURL = "https://jordanmurray.xyz"
@@ -32,35 +93,80 @@ func main() {
 		BaseURL:     rssBaseURL,
 		Title:       "jordanmurray.xyz // reflections",
 		Description: "a personal time capsule in a glass box",
+		Author:      "Jordan Murray",
+	}
+
+	pageSize := models.DefaultPageSize
+	if size := os.Getenv("REFLECTIONS_PAGE_SIZE"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 1 {
+			return "", fmt.Errorf("invalid REFLECTIONS_PAGE_SIZE %q", size)
+		}
+		pageSize = n
 	}
 
-	ctx := context.Background()
+	disallow := []string{"/admin/", "/search"}
+	if paths, ok := os.LookupEnv("ROBOTS_DISALLOW"); ok {
+		disallow = strings.FieldsFunc(paths, func(r rune) bool { return r == ',' })
+	}
+
+	cacheConfig := cache.Config{
+		RSS:      rssConfig,
+		Preview:  os.Getenv("PREVIEW") == "true",
+		PageSize: pageSize,
+		Disallow: disallow,
+	}
 
 	hydrateCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	cache.Hydrate(contentFiles, rssConfig, hydrateCtx)
+	static, err := staticFS()
+	if err != nil {
+		return "", err
+	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "9090"
+	// Assets load first so rendered pages link to fingerprinted URLs.
+	if err := assets.Load(static); err != nil {
+		return "", fmt.Errorf("error loading static assets: %w", err)
 	}
 
-	staticFS, err := fs.Sub(staticFiles, "static")
+	contentFS, err := fs.Sub(contentFiles, "content")
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 
-	http.HandleFunc("GET /{$}", handlers.HandleHome)
-	http.HandleFunc("GET /health", handlers.HandleHealth)
-	http.HandleFunc("GET /reflections", handlers.HandleReflections)
-	http.HandleFunc("GET /reflections/{slug}", handlers.HandleReflection)
-	http.HandleFunc("GET /reflections/feed.rss", handlers.HandleRSS)
-	http.HandleFunc("HEAD /reflections/feed.rss", handlers.HandleRSS)
-	http.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
+	contentDir := os.Getenv("CONTENT_DIR")
+	if contentDir != "" {
+		contentFS = os.DirFS(contentDir)
+	}
 
-	addr := fmt.Sprintf(":%s", port)
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		panic(err)
+	if err := cache.Hydrate(contentFS, cacheConfig, hydrateCtx); err != nil {
+		return "", err
+	}
+
+	return contentDir, nil
+}
+
+func staticFS() (fs.FS, error) {
+	return fs.Sub(staticFiles, "static")
+}
+
+func reloadOnHangup(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+
+		if err := cache.Reload(ctx); err != nil {
+			log.Printf("error reloading content: %v", err)
+			continue
+		}
+		log.Printf("reloaded content")
 	}
 }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"os"
+	"time"
+
+	"jordanmurray.xyz/site/internal/handlers"
+	"jordanmurray.xyz/site/internal/middleware"
+)
+
+// serverConfig holds the HTTP server timeouts, the shutdown schedule, how
+// requests are logged and how often content is polled for changes.
+type serverConfig struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// DrainDelay is how long /readyz reports not ready before the server
+	// stops accepting connections, giving load balancers time to notice.
+	DrainDelay time.Duration
+	// ShutdownTimeout bounds how long in-flight requests get to finish.
+	ShutdownTimeout time.Duration
+	// AccessLog logs each request, or is nil when access logging is off.
+	AccessLog *slog.Logger
+	// TrustedProxies may set X-Forwarded-For for the logged client address.
+	TrustedProxies []netip.Prefix
+	// WatchInterval is how often CONTENT_DIR is polled for changes, or zero
+	// when it is not watched.
+	WatchInterval time.Duration
+}
+
+func loadServerConfig() (serverConfig, error) {
+	config := serverConfig{
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		DrainDelay:        5 * time.Second,
+		ShutdownTimeout:   20 * time.Second,
+	}
+
+	for name, d := range map[string]*time.Duration{
+		"HTTP_READ_HEADER_TIMEOUT": &config.ReadHeaderTimeout,
+		"HTTP_READ_TIMEOUT":        &config.ReadTimeout,
+		"HTTP_WRITE_TIMEOUT":       &config.WriteTimeout,
+		"HTTP_IDLE_TIMEOUT":        &config.IdleTimeout,
+		"SHUTDOWN_DRAIN_DELAY":     &config.DrainDelay,
+		"SHUTDOWN_TIMEOUT":         &config.ShutdownTimeout,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			return serverConfig{}, fmt.Errorf("invalid %s %q", name, value)
+		}
+		*d = parsed
+	}
+
+	if value := os.Getenv("CONTENT_WATCH_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return serverConfig{}, fmt.Errorf("invalid CONTENT_WATCH_INTERVAL %q", value)
+		}
+		config.WatchInterval = interval
+	}
+
+	switch format := os.Getenv("ACCESS_LOG"); format {
+	case "", "json":
+		config.AccessLog = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	case "combined":
+		config.AccessLog = slog.New(middleware.NewCombinedHandler(os.Stdout))
+	case "off":
+	default:
+		return serverConfig{}, fmt.Errorf("invalid ACCESS_LOG %q", format)
+	}
+
+	proxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return serverConfig{}, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	config.TrustedProxies = proxies
+
+	return config, nil
+}
+
+// withMiddleware assigns every request an ID, logs it unless disabled, and
+// records its metrics. Metrics comes last so it sees the matched route.
+func withMiddleware(handler http.Handler, config serverConfig) http.Handler {
+	middlewares := []middleware.Middleware{middleware.WithRequestID}
+	if config.AccessLog != nil {
+		middlewares = append(middlewares, middleware.AccessLog(config.AccessLog, config.TrustedProxies))
+	}
+	middlewares = append(middlewares, middleware.Metrics)
+
+	return middleware.Chain(handler, middlewares...)
+}
+
+// listenAndServe serves handler on addr until ctx is cancelled, then drains:
+// /readyz starts failing, keep-alives are disabled, and after DrainDelay the
+// server stops accepting connections and waits up to ShutdownTimeout for
+// in-flight requests before closing the rest. stop is called as the drain
+// begins so that a second signal kills the process as usual.
+func listenAndServe(ctx context.Context, stop context.CancelFunc, addr string, handler http.Handler, config serverConfig) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		ReadTimeout:       config.ReadTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Printf("shutting down, draining for %s", config.DrainDelay)
+	handlers.Drain()
+	server.SetKeepAlivesEnabled(false)
+	time.Sleep(config.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		_ = server.Close()
+		return fmt.Errorf("error shutting down: %w", err)
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	log.Printf("shut down cleanly")
+	return nil
+}
@@ -1,4 +1,67 @@
 package version
 
+import (
+	"runtime"
+	"runtime/debug"
+)
+
 // GitSHA will be set at build time via -ldflags
 var GitSHA = "unknown"
+
+// BuildTime will be set at build time via -ldflags, as RFC 3339
+var BuildTime = "unknown"
+
+// Info describes the running binary.
+type Info struct {
+	GitSHA    string   `json:"git_sha"`
+	BuildTime string   `json:"build_time"`
+	GoVersion string   `json:"go_version"`
+	Module    Module   `json:"module"`
+	Deps      []Module `json:"dependencies"`
+	// Settings holds the build settings recorded by the go command, such as
+	// the target platform and VCS state.
+	Settings map[string]string `json:"settings"`
+}
+
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+}
+
+// Get reports the build's version information. The git SHA and build time
+// fall back to the VCS stamps the go command embeds when -ldflags were not
+// used.
+func Get() Info {
+	info := Info{
+		GitSHA:    GitSHA,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Settings:  make(map[string]string),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Module = Module{Path: build.Main.Path, Version: build.Main.Version, Sum: build.Main.Sum}
+	for _, dep := range build.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		info.Deps = append(info.Deps, Module{Path: dep.Path, Version: dep.Version, Sum: dep.Sum})
+	}
+	for _, setting := range build.Settings {
+		info.Settings[setting.Key] = setting.Value
+	}
+
+	if info.GitSHA == "unknown" && info.Settings["vcs.revision"] != "" {
+		info.GitSHA = info.Settings["vcs.revision"]
+	}
+	if info.BuildTime == "unknown" && info.Settings["vcs.time"] != "" {
+		info.BuildTime = info.Settings["vcs.time"]
+	}
+
+	return info
+}